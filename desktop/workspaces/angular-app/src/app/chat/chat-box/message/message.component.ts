@@ -14,6 +14,7 @@ import {
 	Output,
 	EventEmitter,
 	ChangeDetectionStrategy,
+	ChangeDetectorRef,
 } from '@angular/core';
 import {
 	ChatService,
@@ -40,9 +41,11 @@ export class MessageComponent {
 		private chatService: ChatService,
 		private promptService: PromptService,
 		private localtron: LocaltronService,
+		private cd: ChangeDetectorRef,
 		public mobile: MobileService
 	) {}
 	hasAsset = false;
+	assetUrl = '';
 
 	@Input() message!: Message;
 	@Input() assets: Asset[] = [];
@@ -51,9 +54,10 @@ export class MessageComponent {
 
 	@Output() onCopyToClipboard = new EventEmitter<string>();
 
-	ngOnInit() {
+	async ngOnInit() {
 		if (this.assets?.length) {
 			this.hasAsset = true;
+			await this.loadAsset(this.message);
 		}
 	}
 
@@ -70,10 +74,17 @@ export class MessageComponent {
 		});
 	}
 
-	asset(message: Message): string {
-		return ('data:image/png;base64,' +
-			this.assets.find((a) => message.assetIds?.includes(a.id))
-				?.content) as string;
+	async loadAsset(message: Message) {
+		const asset = this.assets.find((a) => message.assetIds?.includes(a.id));
+		if (!asset) {
+			return;
+		}
+		try {
+			this.assetUrl = await this.chatService.chatAssetUrl(asset);
+		} catch (error) {
+			console.error('Error loading asset', error);
+		}
+		this.cd.markForCheck();
 	}
 
 	deleteMessage(messageId: string | undefined) {
@@ -12,6 +12,7 @@ import { Injectable } from '@angular/core';
 import { LocaltronService } from './localtron.service';
 import { ReplaySubject } from 'rxjs';
 import { FirehoseService } from './firehose.service';
+import { ImageParameters } from './model.service';
 
 @Injectable({
 	providedIn: 'root',
@@ -28,6 +29,9 @@ export class ChatService {
 	onThreadUpdateSubject = new ReplaySubject<MessageAddedEvent>(1);
 	onThreadUpdate$ = this.onMessageAddedSubject.asObservable();
 
+	/* Object URLs of downloaded assets by asset id */
+	private assetUrls = new Map<string, string>();
+
 	constructor(
 		private localtron: LocaltronService,
 		private firehoseService: FirehoseService
@@ -59,6 +63,33 @@ export class ChatService {
 		return this.localtron.call('/chat/message/delete', request);
 	}
 
+	async chatMessageSelect(messageId: string): Promise<void> {
+		const request: SelectMessageRequest = { messageId: messageId };
+		return this.localtron.call('/chat/message/select', request);
+	}
+
+	async chatAsset(assetId: string): Promise<Blob> {
+		return this.localtron.blob('/chat/asset/' + assetId);
+	}
+
+	/* Returns an object URL of the content of an asset that can be used as an image source */
+	async chatAssetUrl(asset: Asset): Promise<string> {
+		if (asset.content) {
+			return 'data:' + (asset.type || 'image/png') + ';base64,' + asset.content;
+		}
+		const cached = this.assetUrls.get(asset.id);
+		if (cached) {
+			return cached;
+		}
+		const url = URL.createObjectURL(await this.chatAsset(asset.id));
+		this.assetUrls.set(asset.id, url);
+		return url;
+	}
+
+	async chatSearch(request: SearchRequest): Promise<SearchResponse> {
+		return this.localtron.call('/chat/search', request);
+	}
+
 	async chatMessages(threadId: string): Promise<GetMessagesResponse> {
 		const request: GetMessagesRequest = { threadId: threadId };
 		return this.localtron.call('/chat/messages', request);
@@ -86,6 +117,23 @@ export class ChatService {
 		return this.localtron.call('/chat/thread/delete', request);
 	}
 
+	async chatThreadExport(
+		threadId: string,
+		format: ExportFormat
+	): Promise<Blob> {
+		const request: ExportThreadRequest = {
+			threadId: threadId,
+			format: format,
+		};
+		return this.localtron.blob('/chat/thread/export', request);
+	}
+
+	async chatThreadImport(
+		request: ImportThreadRequest
+	): Promise<ImportThreadResponse> {
+		return this.localtron.call('/chat/thread/import', request);
+	}
+
 	async chatThreads(): Promise<GetThreadsResponse> {
 		const request: GetThreadsRequest = {};
 		return this.localtron.call('/chat/threads', request);
@@ -113,6 +161,16 @@ export interface Thread {
 	userIds?: string[];
 
 	title?: string;
+
+	/*
+		Prompt template of the thread and the values of its variables
+	*/
+	templateId?: string;
+	templateVariables?: { [name: string]: string };
+	/*
+		Document collections prompts of the thread are answered from
+	*/
+	collectionIds?: string[];
 }
 
 export interface Message {
@@ -121,17 +179,65 @@ export interface Message {
 	updatedAt?: string;
 
 	threadId: string;
+	/*
+		Message this one follows, the thread id for the first messages.
+		Messages with the same parent are alternative branches.
+	*/
+	parentId?: string;
+	selectedAt?: string;
 	userId?: string;
 	content: string;
 	assetIds: string[];
+	/*
+		Parsed JSON of answers to prompts with a response schema
+	*/
+	structured?: any;
+	role?: 'user' | 'assistant' | 'system' | 'tool';
+	/*
+		Set on answers calling a tool instead of answering
+	*/
+	toolCall?: ToolCall;
+	/*
+		Id of the tool call the result of a tool message is for
+	*/
+	toolCallId?: string;
+	/*
+		Document passages the answer was given from
+	*/
+	citations?: Citation[];
+	/*
+		Parameters the images of the message were generated with
+	*/
+	imageParameters?: ImageParameters;
+}
+
+export interface Citation {
+	index: number;
+	collectionId: string;
+	documentId: string;
+	documentName: string;
+	chunk: number;
+	text: string;
+	score: number;
+}
+
+export interface ToolCall {
+	id: string;
+	name: string;
+	arguments?: { [key: string]: any };
 }
 
 export interface Asset {
 	id: string;
+	/* Url the content can be downloaded from, eg. /chat/asset/{id} */
 	url: string;
-	/* Some assets might have the content directly in them as base64
+	/* Key of the content in the file store */
+	storageKey?: string;
+	/* Size of the content in bytes */
+	size?: number;
+	/* Legacy assets might have the content directly in them as base64
 	encoded strings */
-	content: string;
+	content?: string;
 	type: string;
 	decription: string;
 	createdAt: string;
@@ -185,8 +291,91 @@ type GetThreadsResponse = {
 type GetMessagesRequest = {};
 
 type GetMessagesResponse = {
+	/*
+		Messages of the active branch of the thread
+	*/
 	messages: Message[];
 	assets: Asset[];
+	/*
+		Ids of the alternatives (including itself) of the
+		messages of the active branch that have any
+	*/
+	alternatives?: { [messageId: string]: string[] };
+};
+
+export type ExportFormat = 'markdown' | 'json' | 'openai';
+
+export interface ExportThreadRequest {
+	threadId: string;
+	format: ExportFormat;
+}
+
+/* A thread with all its messages and assets, the content of assets inlined as base64 */
+export interface ThreadBundle {
+	version: number;
+	thread: Thread;
+	messages: Message[];
+	assets?: Asset[];
+}
+
+/* A line of an OpenAI fine-tuning dataset */
+export interface OpenAIConversation {
+	messages: OpenAIMessage[];
+}
+
+export interface OpenAIMessage {
+	role: string;
+	content: string;
+	tool_calls?: {
+		id: string;
+		type: string;
+		function: { name: string; arguments: string };
+	}[];
+	tool_call_id?: string;
+}
+
+/* Exactly one of bundle and conversation must be set */
+export interface ImportThreadRequest {
+	bundle?: ThreadBundle;
+	conversation?: OpenAIConversation;
+	title?: string;
+}
+
+export interface ImportThreadResponse {
+	thread: Thread;
+}
+
+export interface SearchRequest {
+	/* Every word must be the start of a word in a message or a thread title */
+	query: string;
+	/* Optionally restricts the search to a thread */
+	threadId?: string;
+	/* Maximum number of results, 20 by default */
+	limit?: number;
+}
+
+export interface SearchResult {
+	thread: Thread;
+	/* Empty when the thread title matched */
+	message?: Message;
+	/* Part of the matching text around the first match */
+	snippet: string;
+	/* Matched words of the snippet.
+	Offsets are in code points, use Array.from(snippet) to slice. */
+	highlights?: Highlight[];
+}
+
+export interface Highlight {
+	start: number;
+	end: number;
+}
+
+export interface SearchResponse {
+	results: SearchResult[];
+}
+
+type SelectMessageRequest = {
+	messageId: string;
 };
 
 export interface MessageAddedEvent {
@@ -72,6 +72,10 @@ export interface Config {
 
 	model?: {
 		currentModelId?: string;
+		/** Minutes a model can go unused before it is stopped, 0 keeps models running */
+		idleTimeoutMinutes?: number;
+		/** Overrides idleTimeoutMinutes by model id, negative keeps the model running */
+		modelIdleTimeoutMinutes?: { [modelId: string]: number };
 	};
 
 	/** This flag drives a minor UX feature:
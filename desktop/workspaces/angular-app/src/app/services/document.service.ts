@@ -0,0 +1,142 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+import { Injectable } from '@angular/core';
+import { LocaltronService } from './localtron.service';
+
+@Injectable({
+	providedIn: 'root',
+})
+export class DocumentService {
+	constructor(private localtron: LocaltronService) {}
+
+	async collectionSave(
+		collection: Collection
+	): Promise<SaveCollectionResponse> {
+		const request: SaveCollectionRequest = {
+			collection: collection,
+		};
+
+		return this.localtron.call('/document/collection/save', request);
+	}
+
+	async collectionList(): Promise<ListCollectionsResponse> {
+		const request: ListCollectionsRequest = {};
+
+		return this.localtron.call('/document/collection/list', request);
+	}
+
+	async collectionDelete(
+		collectionId: string
+	): Promise<DeleteCollectionResponse> {
+		const request: DeleteCollectionRequest = {
+			collectionId: collectionId,
+		};
+
+		return this.localtron.call('/document/collection/delete', request);
+	}
+
+	async documentAdd(request: AddDocumentRequest): Promise<AddDocumentResponse> {
+		return this.localtron.call('/document/add', request);
+	}
+
+	async documentList(collectionId: string): Promise<ListDocumentsResponse> {
+		const request: ListDocumentsRequest = {
+			collectionId: collectionId,
+		};
+
+		return this.localtron.call('/document/list', request);
+	}
+
+	async documentDelete(documentId: string): Promise<DeleteDocumentResponse> {
+		const request: DeleteDocumentRequest = {
+			documentId: documentId,
+		};
+
+		return this.localtron.call('/document/delete', request);
+	}
+}
+
+export interface Collection {
+	id?: string;
+	createdAt?: string;
+	updatedAt?: string;
+	userId?: string;
+	name: string;
+	description?: string;
+	/*
+		Model the documents are embedded with,
+		set when the first document is added
+	*/
+	modelId?: string;
+}
+
+export interface Document {
+	id: string;
+	createdAt: string;
+	userId: string;
+	collectionId: string;
+	name: string;
+	contentType: 'text/plain' | 'text/markdown';
+	size: number;
+	chunkCount: number;
+}
+
+export interface SaveCollectionRequest {
+	collection: Collection;
+}
+
+export interface SaveCollectionResponse {
+	collection: Collection;
+}
+
+export interface ListCollectionsRequest {}
+
+export interface ListCollectionsResponse {
+	collections: Collection[];
+}
+
+export interface DeleteCollectionRequest {
+	collectionId: string;
+}
+
+export interface DeleteCollectionResponse {}
+
+export interface AddDocumentRequest {
+	collectionId: string;
+	/*
+		Adding a document with the same id again replaces the earlier one
+	*/
+	documentId?: string;
+	name: string;
+	/*
+		PDFs and other formats need to be converted to text before upload
+	*/
+	contentType?: 'text/plain' | 'text/markdown';
+	content: string;
+}
+
+export interface AddDocumentResponse {
+	document: Document;
+}
+
+export interface ListDocumentsRequest {
+	collectionId: string;
+}
+
+export interface ListDocumentsResponse {
+	documents: Document[];
+}
+
+export interface DeleteDocumentRequest {
+	documentId: string;
+}
+
+export interface DeleteDocumentResponse {}
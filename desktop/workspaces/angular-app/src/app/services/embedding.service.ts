@@ -0,0 +1,87 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+import { Injectable } from '@angular/core';
+import { LocaltronService } from './localtron.service';
+
+@Injectable({
+	providedIn: 'root',
+})
+export class EmbeddingService {
+	constructor(private localtron: LocaltronService) {}
+
+	async embeddingEmbed(request: EmbedRequest): Promise<EmbedResponse> {
+		return this.localtron.call('/embedding/embed', request);
+	}
+
+	async embeddingSearch(request: SearchRequest): Promise<SearchResponse> {
+		return this.localtron.call('/embedding/search', request);
+	}
+}
+
+export interface Embedding {
+	id: string;
+	createdAt: string;
+	userId: string;
+	collection: string;
+	/*
+		Model which made the vector.
+		Vectors of different models can't be compared.
+	*/
+	modelId: string;
+	text: string;
+	metadata?: { [key: string]: any };
+	vector?: number[];
+}
+
+export interface EmbedItem {
+	/*
+		Embedding a text with the same id again
+		replaces the earlier embedding
+	*/
+	id?: string;
+	text: string;
+	metadata?: { [key: string]: any };
+}
+
+export interface EmbedRequest {
+	modelId?: string;
+	/*
+		Collection to save the embeddings to.
+		The embeddings are not saved when empty.
+	*/
+	collection?: string;
+	items: EmbedItem[];
+}
+
+export interface EmbedResponse {
+	embeddings: Embedding[];
+}
+
+export interface SearchRequest {
+	modelId?: string;
+	collection: string;
+	query?: string;
+	vector?: number[];
+	limit?: number;
+	minScore?: number;
+}
+
+export interface SearchResult {
+	embedding: Embedding;
+	/*
+		Cosine similarity of the embedding and the query
+	*/
+	score: number;
+}
+
+export interface SearchResponse {
+	results: SearchResult[];
+}
@@ -83,6 +83,32 @@ export class LocaltronService {
 		);
 	}
 
+	/* Downloads a file, with a POST when there is a request */
+	blob(path: string, request?: any): Promise<Blob> {
+		const uri = this.config.env.localtronAddress + path;
+
+		const headers = this.headers.set(
+			'Authorization',
+			'Bearer ' + this.cs.get('the_token')
+		);
+
+		if (request !== undefined) {
+			return firstValueFrom(
+				this.http.post(uri, JSON.stringify(request), {
+					headers: headers,
+					responseType: 'blob',
+				})
+			);
+		}
+
+		return firstValueFrom(
+			this.http.get(uri, {
+				headers: headers,
+				responseType: 'blob',
+			})
+		);
+	}
+
 	uuid() {
 		return (
 			generateSegment(8) +
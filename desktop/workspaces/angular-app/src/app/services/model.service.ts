@@ -11,7 +11,9 @@
 import { Injectable } from '@angular/core';
 import { LocaltronService } from './localtron.service';
 import { DockerService } from './docker.service';
-import { ReplaySubject, combineLatest } from 'rxjs';
+import { FirehoseService } from './firehose.service';
+import { ConfigService } from './config.service';
+import { ReplaySubject, Subject, combineLatest } from 'rxjs';
 import {
 	OnModelLaunch,
 	OnModelCheck,
@@ -22,6 +24,9 @@ import {
 })
 export class ModelService {
 	private initInProgress: boolean = false;
+	/** The default model is not started again after it was unloaded until the next prompt */
+	private autoStartPaused: boolean = false;
+	private currentModelId?: string;
 
 	private onModelCheckSubject = new ReplaySubject<OnModelCheck>(1);
 	/** Emitted any time when the currently selected model is checked */
@@ -34,9 +39,19 @@ export class ModelService {
 	private onModelReadySubject = new ReplaySubject<OnModelReady>(1);
 	public onModelReady$ = this.onModelReadySubject.asObservable();
 
+	private onModelLoadedSubject = new Subject<ModelLoadedEvent>();
+	/** Emitted when a started model first answers */
+	public onModelLoaded$ = this.onModelLoadedSubject.asObservable();
+
+	private onModelUnloadedSubject = new Subject<ModelUnloadedEvent>();
+	/** Emitted when a model is stopped or unloaded for being idle */
+	public onModelUnloaded$ = this.onModelUnloadedSubject.asObservable();
+
 	constructor(
 		private localtron: LocaltronService,
-		private dockerService: DockerService
+		private dockerService: DockerService,
+		private firehoseService: FirehoseService,
+		private configService: ConfigService
 	) {
 		// @todo nothing to trigger model start so we resolve to polling
 		setInterval(() => {
@@ -44,6 +59,11 @@ export class ModelService {
 		}, 2000);
 
 		this.listenToModelReady();
+		this.listenToModelEvents();
+
+		this.configService.onConfigUpdate$.subscribe((config) => {
+			this.currentModelId = config?.model?.currentModelId;
+		});
 	}
 
 	models: Model[] = [];
@@ -71,6 +91,30 @@ export class ModelService {
 		});
 	}
 
+	private listenToModelEvents(): void {
+		this.firehoseService.firehoseEvent$.subscribe((event) => {
+			switch (event.name) {
+				case 'modelLoaded': {
+					this.onModelLoadedSubject.next(event.data);
+					break;
+				}
+				case 'modelUnloaded': {
+					/* other models being unloaded leave the default one alone */
+					if (event.data?.modelId === this.currentModelId) {
+						this.autoStartPaused = true;
+					}
+					this.onModelUnloadedSubject.next(event.data);
+					break;
+				}
+				case 'modelCatalogUpdate': {
+					/* models are fetched again on the next check */
+					this.models = [];
+					break;
+				}
+			}
+		});
+	}
+
 	async init() {
 		try {
 			if (this.initInProgress) {
@@ -89,7 +133,7 @@ export class ModelService {
 				this.onModelLaunchSubject.next({});
 			}
 
-			if (rsp?.status?.assetsReady) {
+			if (rsp?.status?.assetsReady && !this.autoStartPaused) {
 				await this.modelStart();
 			}
 		} catch (error) {
@@ -102,6 +146,11 @@ export class ModelService {
 		}
 	}
 
+	/** Starts the default model again after it was unloaded */
+	resumeAutoStart() {
+		this.autoStartPaused = false;
+	}
+
 	async modelStatus(modelId?: string): Promise<ModelStatusResponse> {
 		const request: ModelStatusRequest = {
 			modelId: modelId,
@@ -116,6 +165,13 @@ export class ModelService {
 		return this.localtron.call('/model/start', request);
 	}
 
+	async modelStop(modelId?: string): Promise<ModelStopResponse> {
+		const request: ModelStopRequest = {
+			modelId: modelId,
+		};
+		return this.localtron.call('/model/stop', request);
+	}
+
 	async makeDefault(url?: string) {
 		this.localtron.call('/model/make-default', { url: url });
 	}
@@ -132,6 +188,7 @@ interface ModelStatus {
 	 * - fully loaded.
 	 */
 	running: boolean;
+	/* Address of the container of the model, empty until it is started */
 	address: string;
 }
 
@@ -147,6 +204,24 @@ interface ModelStartRequest {
 	modelId?: string;
 }
 
+interface ModelStopRequest {
+	modelId?: string;
+}
+
+// eslint-disable-next-line
+interface ModelStopResponse {}
+
+export interface ModelLoadedEvent {
+	modelId: string;
+	address: string;
+}
+
+export interface ModelUnloadedEvent {
+	modelId: string;
+	/* 'stopped' or 'idle' */
+	reason: string;
+}
+
 // eslint-disable-next-line
 interface ModelStartResponse {}
 
@@ -155,6 +230,44 @@ export interface Platform {
 	name?: string;
 	version?: number;
 	container: PlatformContainer;
+	/** Set for platforms generating images */
+	image?: ImageSupport;
+}
+
+/** Image generation parameters a platform accepts */
+export interface ImageSupport {
+	imageToImage: boolean;
+	inpainting: boolean;
+	schedulers: string[];
+	minSize: number;
+	maxSize: number;
+	/** Width and height must be a multiple of it */
+	sizeStep: number;
+	maxSteps: number;
+	maxGuidanceScale: number;
+	maxImages: number;
+	defaults: ImageParameters;
+}
+
+/**
+ * Empty fields fall back to the defaults of the platform.
+ * There is no negative prompt as the stable diffusion container has no input for it.
+ */
+export interface ImageParameters {
+	width?: number;
+	height?: number;
+	steps?: number;
+	guidanceScale?: number;
+	/** A random seed is used when empty */
+	seed?: number;
+	scheduler?: string;
+	numImages?: number;
+	/** Asset of the image to refine instead of starting from scratch */
+	inputAssetId?: string;
+	/** Asset of an image whose white pixels mark the part of the input image to paint over */
+	maskAssetId?: string;
+	/** How much the input image is changed, from 0 to 1 */
+	strength?: number;
 }
 
 export interface PlatformContainer {
@@ -14,6 +14,7 @@ import { ReplaySubject, Observable, catchError } from 'rxjs';
 import { FirehoseService } from './firehose.service';
 import { first } from 'rxjs';
 import { UserService } from './user.service';
+import { ImageParameters, ModelService } from './model.service';
 
 @Injectable({
 	providedIn: 'root',
@@ -25,7 +26,8 @@ export class PromptService {
 	constructor(
 		private localtron: LocaltronService,
 		private userService: UserService,
-		private firehoseService: FirehoseService
+		private firehoseService: FirehoseService,
+		private modelService: ModelService
 	) {
 		this.userService.user$.pipe(first()).subscribe(() => {
 			this.init();
@@ -36,6 +38,7 @@ export class PromptService {
 		this.firehoseService.firehoseEvent$.subscribe(async (event) => {
 			switch (event.name) {
 				case 'promptRemoved':
+				case 'promptCanceled':
 				case 'promptProcessingStarted':
 				case 'promptProcessingFinished':
 				case 'promptAdded': {
@@ -62,6 +65,11 @@ export class PromptService {
 		if (!prompt.id) {
 			prompt.id = this.localtron.uuid();
 		}
+		this.modelService.resumeAutoStart();
+		if (prompt.modelId) {
+			// the model might have been unloaded for being idle
+			await this.modelService.modelStart(prompt.modelId);
+		}
 		const request: AddPromptRequest = { prompt: prompt };
 		return this.localtron.call('/prompt/add', request);
 	}
@@ -71,12 +79,61 @@ export class PromptService {
 		return this.localtron.call('/prompt/remove', request);
 	}
 
+	async promptCancel(promptId: string): Promise<void> {
+		const request: CancelPromptRequest = { promptId: promptId };
+		return this.localtron.call('/prompt/cancel', request);
+	}
+
+	async promptRetry(promptId: string): Promise<void> {
+		const request: RetryPromptRequest = { promptId: promptId };
+		return this.localtron.call('/prompt/retry', request);
+	}
+
+	async promptStats(request: GetStatsRequest): Promise<GetStatsResponse> {
+		return this.localtron.call('/prompt/stats', request);
+	}
+
+	async promptQuotaSave(quota: Quota): Promise<SaveQuotaResponse> {
+		const request: SaveQuotaRequest = { quota: quota };
+		return this.localtron.call('/prompt/quota/save', request);
+	}
+
+	async promptQuotaList(): Promise<ListQuotasResponse> {
+		return this.localtron.call('/prompt/quota/list', {});
+	}
+
+	async promptQuotaDelete(quotaId: string): Promise<void> {
+		const request: DeleteQuotaRequest = { quotaId: quotaId };
+		return this.localtron.call('/prompt/quota/delete', request);
+	}
+
+	async promptTemplateSave(template: Template): Promise<SaveTemplateResponse> {
+		const request: SaveTemplateRequest = { template: template };
+		return this.localtron.call('/prompt/template/save', request);
+	}
+
+	async promptTemplateList(): Promise<ListTemplatesResponse> {
+		return this.localtron.call('/prompt/template/list', {});
+	}
+
+	async promptTemplateDelete(templateId: string): Promise<void> {
+		const request: DeleteTemplateRequest = { templateId: templateId };
+		return this.localtron.call('/prompt/template/delete', request);
+	}
+
 	async promptList(): Promise<ListPromptsResponse> {
 		return this.localtron.call('/prompt/list', {});
 	}
 
 	private resubCount = 0;
-	promptSubscribe(threadId: string): Observable<CompletionResponse> {
+	/*
+		lastEventId is the id of the last chunk received before a reconnect,
+		the server only sends the chunks after it
+	*/
+	promptSubscribe(
+		threadId: string,
+		lastEventId?: string
+	): Observable<CompletionResponse> {
 		if (!threadId) {
 			console.log('No thread id');
 			throw 'no thread id';
@@ -96,10 +153,13 @@ export class PromptService {
 					'/prompt/subscribe?threadId=' +
 					threadId;
 
-				const headers = {
+				const headers: { [key: string]: string } = {
 					Authorization: 'Bearer ' + this.userService.getToken(),
 					'Content-Type': 'application/json',
 				};
+				if (lastEventId) {
+					headers['Last-Event-ID'] = lastEventId;
+				}
 
 				fetch(uri, {
 					method: 'GET',
@@ -134,6 +194,11 @@ export class PromptService {
 											for (const line of lines) {
 												const trimmedLine = line.trim();
 
+												if (trimmedLine.startsWith('id:')) {
+													lastEventId = trimmedLine.slice(3).trim();
+													continue;
+												}
+
 												if (
 													trimmedLine === '' ||
 													trimmedLine === 'data: ' ||
@@ -207,7 +272,7 @@ export class PromptService {
 					error: JSON.stringify(error),
 				});
 				this.resubCount++;
-				return this.promptSubscribe(threadId);
+				return this.promptSubscribe(threadId, lastEventId);
 			})
 		);
 	}
@@ -229,6 +294,12 @@ export interface Prompt {
 	id: string;
 	threadId: string;
 	userId?: string;
+	/*
+		Message the prompt follows, defaults to the last message of the active branch.
+		Following the parent of a user message edits or regenerates it
+		as an alternative branch.
+	*/
+	parentId?: string;
 	/*
 		Prompt without template, eg. `What is a banana`?
 	*/
@@ -246,6 +317,135 @@ export interface Prompt {
 	runCount?: number; // How many times this was ran (retries are due to errors)
 	error?: string;
 	maxRetries?: number;
+	/*
+		Higher priority prompts are processed first.
+		Raising it above 0 requires the `prompt.prioritize` permission.
+	*/
+	priority?: number;
+	/*
+		JSON schema the answer must match. The parsed answer
+		is saved as the `structured` field of the answer message.
+	*/
+	responseSchema?: { [key: string]: any };
+	/*
+		Ids of the tools the model can call while answering.
+	*/
+	toolIds?: string[];
+	/*
+		Maximum number of tool calls before the model has to answer.
+		Defaults to 5.
+	*/
+	maxToolSteps?: number;
+	/*
+		Parameters of prompts of image generating platforms
+	*/
+	imageParameters?: ImageParameters;
+	usage?: Usage;
+}
+
+export interface Usage {
+	promptTokens: number;
+	completionTokens: number;
+	generationMs: number;
+}
+
+export interface UsageStat extends Usage {
+	id: string;
+	day: string; // eg. 2024-05-01
+	userId: string;
+	modelId: string;
+	prompts: number;
+}
+
+/*
+	Days are inclusive, empty fields don't filter.
+	Requires the `prompt.stats` permission.
+*/
+export interface GetStatsRequest {
+	from?: string;
+	to?: string;
+	userId?: string;
+	modelId?: string;
+	threadId?: string;
+}
+
+export interface GetStatsResponse {
+	stats: UsageStat[];
+	total: UsageStat;
+}
+
+/*
+	A quota applies either to a role or to a user,
+	the quota of a user takes precedence over the ones of its roles.
+	Zero or missing limits are unlimited.
+*/
+export interface Quota {
+	id?: string;
+	roleId?: string;
+	userId?: string;
+	promptsPerHour?: number;
+	tokensPerDay?: number;
+	maxQueued?: number;
+}
+
+export interface SaveQuotaRequest {
+	quota: Quota;
+}
+
+export interface SaveQuotaResponse {
+	quota: Quota;
+}
+
+export interface ListQuotasResponse {
+	quotas: Quota[];
+}
+
+export interface DeleteQuotaRequest {
+	quotaId: string;
+}
+
+/*
+	A reusable persona. The system prompt and the role templates are
+	Go templates rendered with the variables of the thread,
+	eg. `You review {{.language}} code.`
+	The message still replaces `{prompt}` in the role templates.
+*/
+export interface Template {
+	id?: string;
+	createdAt?: string;
+	updatedAt?: string;
+	userId?: string;
+	name: string;
+	description?: string;
+	systemPrompt?: string;
+	roleTemplates?: {
+		system?: string;
+		user?: string;
+		assistant?: string;
+	};
+	variables?: TemplateVariable[];
+}
+
+export interface TemplateVariable {
+	name: string;
+	description?: string;
+	default?: string;
+}
+
+export interface SaveTemplateRequest {
+	template: Template;
+}
+
+export interface SaveTemplateResponse {
+	template: Template;
+}
+
+export interface ListTemplatesResponse {
+	templates: Template[];
+}
+
+export interface DeleteTemplateRequest {
+	templateId: string;
 }
 
 export interface AddPromptRequest {
@@ -257,10 +457,22 @@ export interface RemovePromptRequest {
 }
 
 // eslint-disable-next-line
+export interface RetryPromptRequest {
+	promptId: string;
+}
+
+export interface CancelPromptRequest {
+	promptId: string;
+}
+
 export interface ListPromptsRequest {}
 
 export interface ListPromptsResponse {
 	prompts: Prompt[];
+	/*
+		Place of the waiting prompts in the queue of their model by prompt id
+	*/
+	queuePositions?: { [promptId: string]: number };
 }
 
 export interface PromptRequest {
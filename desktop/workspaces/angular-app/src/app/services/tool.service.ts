@@ -0,0 +1,77 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+import { Injectable } from '@angular/core';
+import { LocaltronService } from './localtron.service';
+
+@Injectable({
+	providedIn: 'root',
+})
+export class ToolService {
+	constructor(private localtron: LocaltronService) {}
+
+	async toolSave(tool: Tool): Promise<SaveToolResponse> {
+		const request: SaveToolRequest = {
+			tool: tool,
+		};
+
+		return this.localtron.call('/tool/save', request);
+	}
+
+	async toolList(): Promise<ListToolsResponse> {
+		const request: ListToolsRequest = {};
+
+		return this.localtron.call('/tool/list', request);
+	}
+
+	async toolDelete(toolId: string): Promise<DeleteToolResponse> {
+		const request: DeleteToolRequest = {
+			toolId: toolId,
+		};
+
+		return this.localtron.call('/tool/delete', request);
+	}
+}
+
+export interface Tool {
+	/*
+		Name models call the tool by
+	*/
+	id: string;
+	createdAt?: string;
+	updatedAt?: string;
+	description: string;
+	/*
+		JSON schema of the arguments of the tool
+	*/
+	parameters?: { [key: string]: any };
+	type: 'http' | 'builtin';
+	url?: string;
+}
+
+export interface SaveToolRequest {
+	tool: Tool;
+}
+
+export interface SaveToolResponse {
+	tool: Tool;
+}
+
+export interface ListToolsRequest {}
+
+export interface ListToolsResponse {
+	tools: Tool[];
+}
+
+export interface DeleteToolRequest {
+	toolId: string;
+}
+
+export interface DeleteToolResponse {}
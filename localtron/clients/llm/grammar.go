@@ -0,0 +1,265 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+/*
+JSON grammar rules in the GBNF format of llama-cpp.
+Rules consume the whitespace after them.
+*/
+var jsonRules = map[string]string{
+	"ws":      `[ \t\n]*`,
+	"string":  `"\"" ( [^"\\] | "\\" ["\\/bfnrt] | "\\u" [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F] )* "\"" ws`,
+	"number":  `"-"? ( "0" | [1-9] [0-9]* ) ( "." [0-9]+ )? ( [eE] [-+]? [0-9]+ )? ws`,
+	"integer": `"-"? ( "0" | [1-9] [0-9]* ) ws`,
+	"boolean": `( "true" | "false" ) ws`,
+	"null":    `"null" ws`,
+	"value":   `( object | array | string | number | boolean | null )`,
+	"object":  `"{" ws ( string ":" ws value ( "," ws string ":" ws value )* )? "}" ws`,
+	"array":   `"[" ws ( value ( "," ws value )* )? "]" ws`,
+}
+
+// the rules each json rule refers to
+var jsonRuleDependencies = map[string][]string{
+	"string":  {"ws"},
+	"number":  {"ws"},
+	"integer": {"ws"},
+	"boolean": {"ws"},
+	"null":    {"ws"},
+	"value":   {"object", "array", "string", "number", "boolean", "null"},
+	"object":  {"ws", "string", "value"},
+	"array":   {"ws", "value"},
+}
+
+var nonRuleChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+/*
+JSONSchemaToGrammar converts a JSON schema to a GBNF grammar
+which makes llama-cpp generate JSON matching the schema.
+
+Supported keywords are type, properties, items, minItems, enum, const,
+anyOf and oneOf. All properties of an object are generated, which
+satisfies the required keyword too. Other keywords are not
+enforced by the grammar, the answer has to be validated.
+*/
+func JSONSchemaToGrammar(schema map[string]any) (string, error) {
+	g := &grammarBuilder{
+		rules: map[string]string{},
+	}
+
+	root, err := g.visit(schema, "root")
+	if err != nil {
+		return "", err
+	}
+	g.rules["root"] = root
+
+	names := []string{}
+	for name := range g.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("root ::= %v\n", g.rules["root"]))
+	for _, name := range names {
+		if name == "root" {
+			continue
+		}
+		result.WriteString(fmt.Sprintf("%v ::= %v\n", name, g.rules[name]))
+	}
+
+	return result.String(), nil
+}
+
+type grammarBuilder struct {
+	rules map[string]string
+}
+
+// use adds a json rule and the rules it depends on
+func (g *grammarBuilder) use(rule string) string {
+	if _, ok := g.rules[rule]; ok {
+		return rule
+	}
+	g.rules[rule] = jsonRules[rule]
+	for _, dependency := range jsonRuleDependencies[rule] {
+		g.use(dependency)
+	}
+	return rule
+}
+
+// add adds a rule for a part of the schema and returns its name
+func (g *grammarBuilder) add(hint string, body string) string {
+	name := strings.Trim(nonRuleChars.ReplaceAllString(hint, "-"), "-")
+	if name == "" || name == "root" {
+		name = "schema"
+	}
+
+	candidate := name
+	for i := 1; ; i++ {
+		if _, ok := g.rules[candidate]; !ok {
+			break
+		}
+		candidate = fmt.Sprintf("%v-%v", name, i)
+	}
+
+	g.rules[candidate] = body
+	return candidate
+}
+
+// visit returns the grammar expression generating the values of a schema
+func (g *grammarBuilder) visit(schema map[string]any, hint string) (string, error) {
+	if value, ok := schema["const"]; ok {
+		g.use("ws")
+		return literal(value)
+	}
+
+	if values, ok := schema["enum"]; ok {
+		list, ok := values.([]any)
+		if !ok || len(list) == 0 {
+			return "", fmt.Errorf("%v: enum must be a non-empty list", hint)
+		}
+		return g.alternatives(list, hint, func(v any, _ string) (string, error) {
+			return literal(v)
+		})
+	}
+
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		if values, ok := schema[keyword]; ok {
+			list, ok := values.([]any)
+			if !ok || len(list) == 0 {
+				return "", fmt.Errorf("%v: %v must be a non-empty list", hint, keyword)
+			}
+			return g.alternatives(list, hint, func(v any, h string) (string, error) {
+				sub, ok := v.(map[string]any)
+				if !ok {
+					return "", fmt.Errorf("%v: %v must be a list of schemas", hint, keyword)
+				}
+				return g.visit(sub, h)
+			})
+		}
+	}
+
+	switch typ := schema["type"].(type) {
+	case nil:
+		return g.use("value"), nil
+	case string:
+		return g.visitType(schema, typ, hint)
+	case []any:
+		return g.alternatives(typ, hint, func(v any, h string) (string, error) {
+			name, ok := v.(string)
+			if !ok {
+				return "", fmt.Errorf("%v: type must be a string or a list of strings", hint)
+			}
+			return g.visitType(schema, name, h)
+		})
+	}
+
+	return "", fmt.Errorf("%v: type must be a string or a list of strings", hint)
+}
+
+func (g *grammarBuilder) visitType(schema map[string]any, typ string, hint string) (string, error) {
+	switch typ {
+	case "string", "number", "integer", "boolean", "null":
+		return g.use(typ), nil
+
+	case "object":
+		properties, _ := schema["properties"].(map[string]any)
+		if len(properties) == 0 {
+			return g.use("object"), nil
+		}
+
+		keys := []string{}
+		for key := range properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		g.use("ws")
+		parts := []string{`"{" ws`}
+		for i, key := range keys {
+			propertySchema, ok := properties[key].(map[string]any)
+			if !ok {
+				return "", fmt.Errorf("%v: property '%v' must be a schema", hint, key)
+			}
+			value, err := g.visit(propertySchema, hint+"-"+key)
+			if err != nil {
+				return "", err
+			}
+			name, err := literal(key)
+			if err != nil {
+				return "", err
+			}
+			if i > 0 {
+				parts = append(parts, `"," ws`)
+			}
+			parts = append(parts, name, `":" ws`, value)
+		}
+		parts = append(parts, `"}" ws`)
+
+		return g.add(hint, strings.Join(parts, " ")), nil
+
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		if items == nil {
+			return g.use("array"), nil
+		}
+
+		item, err := g.visit(items, hint+"-item")
+		if err != nil {
+			return "", err
+		}
+
+		g.use("ws")
+		minItems, _ := schema["minItems"].(float64)
+		if minItems >= 1 {
+			return g.add(hint, fmt.Sprintf(`"[" ws %v ( "," ws %v )* "]" ws`, item, item)), nil
+		}
+		return g.add(hint, fmt.Sprintf(`"[" ws ( %v ( "," ws %v )* )? "]" ws`, item, item)), nil
+	}
+
+	return "", fmt.Errorf("%v: unsupported type '%v'", hint, typ)
+}
+
+func (g *grammarBuilder) alternatives(
+	values []any,
+	hint string,
+	visit func(v any, hint string) (string, error),
+) (string, error) {
+	options := []string{}
+	for i, v := range values {
+		option, err := visit(v, fmt.Sprintf("%v-%v", hint, i))
+		if err != nil {
+			return "", err
+		}
+		options = append(options, option)
+	}
+
+	g.use("ws")
+	return "( " + strings.Join(options, " | ") + " )", nil
+}
+
+// literal is a grammar string matching a value as JSON followed by whitespace
+func literal(value any) (string, error) {
+	bs, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(string(bs))
+	return `"` + escaped + `" ws`, nil
+}
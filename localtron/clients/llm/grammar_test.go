@@ -0,0 +1,76 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package llm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchemaToGrammar(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		expected string
+		err      bool
+	}{
+		{
+			name:   "Object",
+			schema: `{"type": "object", "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}}`,
+			expected: `root ::= schema
+integer ::= "-"? ( "0" | [1-9] [0-9]* ) ws
+schema ::= "{" ws "\"age\"" ws ":" ws integer "," ws "\"name\"" ws ":" ws string "}" ws
+string ::= "\"" ( [^"\\] | "\\" ["\\/bfnrt] | "\\u" [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F] [0-9a-fA-F] )* "\"" ws
+ws ::= [ \t\n]*
+`,
+		},
+		{
+			name:   "Array of enums",
+			schema: `{"type": "array", "minItems": 1, "items": {"enum": ["red", "green"]}}`,
+			expected: `root ::= schema
+schema ::= "[" ws ( "\"red\"" ws | "\"green\"" ws ) ( "," ws ( "\"red\"" ws | "\"green\"" ws ) )* "]" ws
+ws ::= [ \t\n]*
+`,
+		},
+		{
+			name:   "Nullable",
+			schema: `{"type": ["boolean", "null"]}`,
+			expected: `root ::= ( boolean | null )
+boolean ::= ( "true" | "false" ) ws
+null ::= "null" ws
+ws ::= [ \t\n]*
+`,
+		},
+		{
+			name:   "Unsupported type",
+			schema: `{"type": "date"}`,
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(tt.schema), &schema))
+
+			grammar, err := JSONSchemaToGrammar(schema)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, grammar)
+		})
+	}
+}
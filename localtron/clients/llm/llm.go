@@ -13,11 +13,14 @@ package llm
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log/slog"
 	"net/http"
+	"sort"
 
 	"github.com/singulatron/singulatron/localtron/logger"
 )
@@ -31,31 +34,43 @@ func NewClient() *Client {
 }
 
 type PostCompletionsRequest struct {
-	Prompt    string `json:"prompt,omitempty"`
-	MaxTokens int    `json:"max_tokens,omitempty"`
-	Stream    bool   `json:"stream,omitempty"`
+	Prompt      string   `json:"prompt,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	Stream      bool     `json:"stream,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	TopK        *int     `json:"top_k,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	// Grammar constrains the answer, see JSONSchemaToGrammar
+	Grammar string `json:"grammar,omitempty"`
+}
+
+type CompletionChoice struct {
+	Text         string      `json:"text,omitempty"`
+	Index        int         `json:"index,omitempty"`
+	Logprobs     interface{} `json:"logprobs,omitempty"`
+	FinishReason string      `json:"finish_reason,omitempty"`
 }
 
 type CompletionResponse struct {
-	ID      string `json:"id,omitempty"`
-	Object  string `json:"object,omitempty"`
-	Created int64  `json:"created,omitempty"`
-	Model   string `json:"model,omitempty"`
-	Choices []struct {
-		Text         string      `json:"text,omitempty"`
-		Index        int         `json:"index,omitempty"`
-		Logprobs     interface{} `json:"logprobs,omitempty"`
-		FinishReason string      `json:"finish_reason,omitempty"`
-	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens,omitempty"`
-		CompletionTokens int `json:"completion_tokens,omitempty"`
-		TotalTokens      int `json:"total_tokens,omitempty"`
-	} `json:"usage,omitempty"`
+	ID      string             `json:"id,omitempty"`
+	Object  string             `json:"object,omitempty"`
+	Created int64              `json:"created,omitempty"`
+	Model   string             `json:"model,omitempty"`
+	Choices []CompletionChoice `json:"choices"`
+	Usage   Usage              `json:"usage,omitempty"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens,omitempty"`
+	CompletionTokens int `json:"completion_tokens,omitempty"`
+	TotalTokens      int `json:"total_tokens,omitempty"`
 }
 
 // Must be only used by the prompt service
-func (c *Client) PostCompletions(prompt PostCompletionsRequest) (*CompletionResponse, error) {
+// Canceling the context aborts the request.
+func (c *Client) PostCompletions(ctx context.Context, prompt PostCompletionsRequest) (*CompletionResponse, error) {
 	if prompt.Stream {
 		return nil, errors.New("streamed completions not supported by this method")
 	}
@@ -67,7 +82,7 @@ func (c *Client) PostCompletions(prompt PostCompletionsRequest) (*CompletionResp
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", address+"/v1/completions", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", address+"/v1/completions", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +121,8 @@ func (c *Client) PostCompletions(prompt PostCompletionsRequest) (*CompletionResp
 type StreamCallback func(*CompletionResponse)
 
 // Must be only used by the prompt service
-func (c *Client) PostCompletionsStreamed(prompt PostCompletionsRequest, callback StreamCallback) error {
+// Canceling the context aborts the stream.
+func (c *Client) PostCompletionsStreamed(ctx context.Context, prompt PostCompletionsRequest, callback StreamCallback) error {
 	address := c.LLMAddress
 
 	jsonBody, err := json.Marshal(prompt)
@@ -114,7 +130,7 @@ func (c *Client) PostCompletionsStreamed(prompt PostCompletionsRequest, callback
 		return err
 	}
 
-	req, err := http.NewRequest("POST", address+"/v1/completions", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", address+"/v1/completions", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return err
 	}
@@ -161,3 +177,107 @@ func (c *Client) PostCompletionsStreamed(prompt PostCompletionsRequest, callback
 
 	return nil
 }
+
+type TokenizeRequest struct {
+	Input string `json:"input"`
+}
+
+type TokenizeResponse struct {
+	Tokens []int `json:"tokens"`
+}
+
+// Tokenize returns the tokens of a text as seen by the loaded model.
+// Must be only used by the prompt service
+func (c *Client) Tokenize(text string) ([]int, error) {
+	jsonBody, err := json.Marshal(TokenizeRequest{
+		Input: text,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", c.LLMAddress+"/extras/tokenize", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("API request failed with status code: " + resp.Status)
+	}
+
+	var tokenizeResp TokenizeResponse
+	err = json.NewDecoder(resp.Body).Decode(&tokenizeResp)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenizeResp.Tokens, nil
+}
+
+type EmbeddingsRequest struct {
+	Input []string `json:"input"`
+}
+
+type EmbeddingData struct {
+	Object    string    `json:"object,omitempty"`
+	Index     int       `json:"index"`
+	Embedding []float64 `json:"embedding"`
+}
+
+type EmbeddingsResponse struct {
+	Object string          `json:"object,omitempty"`
+	Model  string          `json:"model,omitempty"`
+	Data   []EmbeddingData `json:"data"`
+	Usage  Usage           `json:"usage,omitempty"`
+}
+
+// PostEmbeddings returns the embedding vectors of the inputs
+// in the order of the inputs.
+func (c *Client) PostEmbeddings(request EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	jsonBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", c.LLMAddress+"/v1/embeddings", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("API request failed with status code: " + resp.Status)
+	}
+
+	var embeddingsResp EmbeddingsResponse
+	err = json.NewDecoder(resp.Body).Decode(&embeddingsResp)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(embeddingsResp.Data) != len(request.Input) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(request.Input), len(embeddingsResp.Data))
+	}
+	sort.Slice(embeddingsResp.Data, func(i, j int) bool {
+		return embeddingsResp.Data[i].Index < embeddingsResp.Data[j].Index
+	})
+
+	return &embeddingsResp, nil
+}
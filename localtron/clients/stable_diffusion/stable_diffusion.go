@@ -12,7 +12,7 @@ package stable_diffusion
 
 import (
 	"bytes"
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -32,6 +32,11 @@ func NewClient(address string) *Client {
 	return &Client{Address: address}
 }
 
+// FnIndexInference is the index of the inference function of the container.
+// It does text-to-image, image-to-image when given an input image
+// and inpainting when also given a mask.
+const FnIndexInference = 1
+
 // represents
 // "data":["draw me a cat",1,6,256,256,7.5,0,false,false,"PNDM",0.25,null,null,null]
 type StableDiffusionParams struct {
@@ -45,10 +50,14 @@ type StableDiffusionParams struct {
 	Flag1         bool    `json:"flag1"`
 	Flag2         bool    `json:"flag2"`
 	Scheduler     string  `json:"scheduler"`
-	Rate          float64 `json:"rate"`
-	Optional1     *string `json:"optional1"`
-	Optional2     *string `json:"optional2"`
-	Optional3     *string `json:"optional3"`
+	// Strength is how much the input image is changed, from 0 to 1
+	Strength float64 `json:"strength"`
+	// InputImage is the data URL of the image to start from, see ImageDataURL
+	InputImage *string `json:"input_image"`
+	// Mask is the data URL of an image whose white pixels mark
+	// the part of the input image to paint over
+	Mask      *string `json:"mask"`
+	Optional3 *string `json:"optional3"`
 }
 
 type PredictRequest struct {
@@ -71,13 +80,18 @@ func (pr *PredictRequest) ConvertParamsToData() {
 		pr.Params.Flag1,
 		pr.Params.Flag2,
 		pr.Params.Scheduler,
-		pr.Params.Rate,
-		pr.Params.Optional1,
-		pr.Params.Optional2,
+		pr.Params.Strength,
+		pr.Params.InputImage,
+		pr.Params.Mask,
 		pr.Params.Optional3,
 	}
 }
 
+// ImageDataURL returns the data URL images are sent to the container as
+func ImageDataURL(contentType string, base64Content string) string {
+	return fmt.Sprintf("data:%v;base64,%v", contentType, base64Content)
+}
+
 type FileData struct {
 	Name   string      `json:"name"`
 	Data   interface{} `json:"data"`
@@ -153,29 +167,22 @@ func FileURL(addr string, fileName string) string {
 	return fmt.Sprintf("%v:/file=%v", addr, fileName)
 }
 
-// GetImageAsBase64 fetches the image from the given URL and returns it as a base64 encoded string.
-func GetImageAsBase64(imageURL string) (string, error) {
+// GetImage fetches the image from the given URL. The caller must close the returned body.
+func GetImage(imageURL string) (io.ReadCloser, string, error) {
 	resp, err := http.Get(imageURL)
 	if err != nil {
-		return "", errors.New("failed to fetch image: " + err.Error())
+		return nil, "", errors.New("failed to fetch image: " + err.Error())
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", errors.New("failed to fetch image: status code " + resp.Status)
+		resp.Body.Close()
+		return nil, "", errors.New("failed to fetch image: status code " + resp.Status)
 	}
 
-	imageData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", errors.New("failed to read image data: " + err.Error())
-	}
-
-	base64Image := base64.StdEncoding.EncodeToString(imageData)
-
-	return base64Image, nil
+	return resp.Body, resp.Header.Get("Content-Type"), nil
 }
 
-func (c *Client) Predict(req PredictRequest) (*PredictResponse, error) {
+func (c *Client) Predict(ctx context.Context, req PredictRequest) (*PredictResponse, error) {
 	url := c.Address + "/run/predict/"
 
 	jsonBody, err := json.Marshal(req)
@@ -183,7 +190,7 @@ func (c *Client) Predict(req PredictRequest) (*PredictResponse, error) {
 		return nil, err
 	}
 
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, err
 	}
@@ -53,3 +53,27 @@ func TestResponseUnmarshal(t *testing.T) {
 	assert.Equal(t, 2, len(rsp.Data), rsp.Data)
 	assert.Equal(t, "/tmp/tmpj74v2rly/tmpr1li4qkz.png", rsp.Data[0].FileData[0].Name)
 }
+
+func TestConvertParamsToData(t *testing.T) {
+	image := ImageDataURL("image/png", "aGk=")
+	assert.Equal(t, "data:image/png;base64,aGk=", image)
+
+	req := PredictRequest{
+		FnIndex: FnIndexInference,
+		Params: StableDiffusionParams{
+			Prompt:     "draw me a cat",
+			NumImages:  1,
+			Scheduler:  "PNDM",
+			Strength:   0.25,
+			InputImage: &image,
+		},
+	}
+	req.ConvertParamsToData()
+
+	bs, err := json.Marshal(req)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"fn_index":1,"data":["draw me a cat",1,0,0,0,0,0,false,false,"PNDM",0.25,"data:image/png;base64,aGk=",null,null],"session_hash":""}`,
+		string(bs),
+	)
+}
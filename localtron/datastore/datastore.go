@@ -67,6 +67,7 @@ type QueryBuilder[T Row] interface {
 type Condition struct {
 	Equal *EqualCondition `json:"equal,omitempty"`
 	All   *AllCondition   `json:"all,omitempty"`
+	Match *MatchCondition `json:"match,omitempty"`
 }
 
 type EqualCondition struct {
@@ -77,6 +78,16 @@ type EqualCondition struct {
 type AllCondition struct {
 }
 
+/*
+MatchCondition is a full text condition.
+It matches rows where every word of the query
+is the start of a word in the text field.
+*/
+type MatchCondition struct {
+	FieldName string `json:"fieldName,omitempty"`
+	Query     string `json:"query,omitempty"`
+}
+
 func Equal(fieldName string, value any) Condition {
 	return Condition{
 		Equal: &EqualCondition{
@@ -92,6 +103,15 @@ func All() Condition {
 	}
 }
 
+func Match(fieldName string, query string) Condition {
+	return Condition{
+		Match: &MatchCondition{
+			FieldName: fieldName,
+			Query:     query,
+		},
+	}
+}
+
 func Id(id string) Condition {
 	return Condition{
 		Equal: &EqualCondition{
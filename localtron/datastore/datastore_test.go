@@ -91,6 +91,7 @@ func TestAll(t *testing.T) {
 		"Pagination":             Pagination,
 		"FindOne":                FindOne,
 		"Update":                 Update,
+		"Match":                  Match,
 	}
 	pointerTests := map[string]func(t *testing.T, store datastore.DataStore[*TestObject]){
 		"PointerCreate":                 PointerCreate,
@@ -106,6 +107,7 @@ func TestAll(t *testing.T) {
 		"PointerPagination":             PointerPagination,
 		"PointerFindOne":                PointerFindOne,
 		"PointerUpdate":                 PointerUpdate,
+		"PointerMatch":                  PointerMatch,
 	}
 
 	for testName, test := range tests {
@@ -245,6 +247,42 @@ func PointerCreatedAt(t *testing.T, store datastore.DataStore[*TestObject]) {
 	require.Equal(t, "A2", res[0].Name)
 }
 
+func Match(t *testing.T, store datastore.DataStore[TestObject]) {
+	obj1 := TestObject{Name: "Hello World", Value: 10}
+	obj2 := TestObject{Name: "help desk", Value: 20}
+	obj3 := TestObject{Name: "other", Value: 10}
+
+	err := store.CreateMany([]TestObject{obj1, obj2, obj3})
+	require.NoError(t, err)
+
+	res, err := store.Query(datastore.Match("name", "hel")).Find()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(res))
+
+	res, err = store.Query(datastore.Match("name", "world, HEL")).Find()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(res))
+	require.Equal(t, obj1.Name, res[0].Name)
+
+	count, err := store.Query(
+		datastore.Match("name", "hel"),
+		datastore.Equal("value", 20),
+	).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	count, err = store.Query(datastore.Match("name", "")).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), count)
+
+	err = store.Query(datastore.Equal("name", obj1.Name)).Delete()
+	require.NoError(t, err)
+
+	res, err = store.Query(datastore.Match("name", "hello")).Find()
+	require.NoError(t, err)
+	require.Equal(t, 0, len(res))
+}
+
 func FindOne(t *testing.T, store datastore.DataStore[TestObject]) {
 	obj1 := TestObject{Name: "A1", Value: 10, CreatedAt: time.Now()}
 	obj2 := TestObject{Name: "A2", Value: 10, CreatedAt: time.Now().Add(time.Minute)}
@@ -266,6 +304,42 @@ func FindOne(t *testing.T, store datastore.DataStore[TestObject]) {
 	require.Equal(t, obj3.Name, res.Name)
 }
 
+func PointerMatch(t *testing.T, store datastore.DataStore[*TestObject]) {
+	obj1 := &TestObject{Name: "Hello World", Value: 10}
+	obj2 := &TestObject{Name: "help desk", Value: 20}
+	obj3 := &TestObject{Name: "other", Value: 10}
+
+	err := store.CreateMany([]*TestObject{obj1, obj2, obj3})
+	require.NoError(t, err)
+
+	res, err := store.Query(datastore.Match("name", "hel")).Find()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(res))
+
+	res, err = store.Query(datastore.Match("name", "world, HEL")).Find()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(res))
+	require.Equal(t, obj1.Name, res[0].Name)
+
+	count, err := store.Query(
+		datastore.Match("name", "hel"),
+		datastore.Equal("value", 20),
+	).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	count, err = store.Query(datastore.Match("name", "")).Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), count)
+
+	err = store.Query(datastore.Equal("name", obj1.Name)).Delete()
+	require.NoError(t, err)
+
+	res, err = store.Query(datastore.Match("name", "hello")).Find()
+	require.NoError(t, err)
+	require.Equal(t, 0, len(res))
+}
+
 func PointerFindOne(t *testing.T, store datastore.DataStore[*TestObject]) {
 	obj1 := &TestObject{Name: "A1", Value: 10, CreatedAt: time.Now()}
 	obj2 := &TestObject{Name: "A2", Value: 10, CreatedAt: time.Now().Add(time.Minute)}
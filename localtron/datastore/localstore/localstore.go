@@ -32,6 +32,10 @@ type LocalStore[T datastore.Row] struct {
 	inTransaction bool
 	originalStore *LocalStore[T] // Reference to the original store in case of transaction
 	stateManager  *statemanager.StateManager[T]
+
+	// textIndexes are the inverted indexes of fields used in match conditions
+	textIndexes map[string]*textIndex
+	indexMu     sync.Mutex
 }
 
 func NewLocalStore[T datastore.Row](filePath string) *LocalStore[T] {
@@ -84,6 +88,7 @@ func (s *LocalStore[T]) createWithoutLock(obj T) error {
 		return datastore.ErrEntryAlreadyExists
 	}
 	s.data[id] = obj
+	s.indexRow(id, obj)
 	s.stateManager.MarkChanged()
 	return nil
 }
@@ -103,6 +108,7 @@ func (s *LocalStore[T]) CreateMany(objs []T) error {
 	for _, obj := range objs {
 		id := obj.GetId()
 		s.data[id] = obj
+		s.indexRow(id, obj)
 	}
 
 	s.stateManager.MarkChanged()
@@ -114,6 +120,7 @@ func (s *LocalStore[T]) Upsert(obj T) error {
 	defer s.mu.Unlock()
 
 	s.data[obj.GetId()] = obj
+	s.indexRow(obj.GetId(), obj)
 	s.stateManager.MarkChanged()
 	return nil
 }
@@ -124,6 +131,7 @@ func (s *LocalStore[T]) UpsertMany(objs []T) error {
 
 	for _, obj := range objs {
 		s.data[obj.GetId()] = obj
+		s.indexRow(obj.GetId(), obj)
 	}
 	s.stateManager.MarkChanged()
 	return nil
@@ -171,6 +179,7 @@ func (s *LocalStore[T]) Commit() error {
 	// Apply the changes to the original store
 	for k, v := range s.data {
 		s.originalStore.data[k] = v
+		s.originalStore.indexRow(k, v)
 	}
 
 	// Reset transaction state
@@ -206,6 +215,9 @@ type QueryBuilder[T datastore.Row] struct {
 	limit        int
 	after        []any
 	selectFields []string
+
+	// matches are the ids matching the full text conditions, by condition index
+	matches map[int]map[string]struct{}
 }
 
 func (q *QueryBuilder[T]) OrderBy(field string, desc bool) datastore.QueryBuilder[T] {
@@ -232,9 +244,10 @@ func (q *QueryBuilder[T]) Select(fields ...string) datastore.QueryBuilder[T] {
 func (q *QueryBuilder[T]) Find() ([]T, error) {
 	q.store.mu.RLock()
 	defer q.store.mu.RUnlock()
+	q.searchText()
 
 	var result []T
-	for _, obj := range q.store.data {
+	for _, obj := range q.candidates() {
 		if q.match(obj) {
 			result = append(result, obj)
 		}
@@ -272,6 +285,7 @@ func (q *QueryBuilder[T]) Find() ([]T, error) {
 func (q *QueryBuilder[T]) FindOne() (T, bool, error) {
 	q.store.mu.RLock()
 	defer q.store.mu.RUnlock()
+	q.searchText()
 	for _, obj := range q.store.data {
 		if q.match(obj) {
 			return obj, true, nil
@@ -284,6 +298,7 @@ func (q *QueryBuilder[T]) FindOne() (T, bool, error) {
 func (q *QueryBuilder[T]) Count() (int64, error) {
 	q.store.mu.RLock()
 	defer q.store.mu.RUnlock()
+	q.searchText()
 
 	var count int64
 	for _, obj := range q.store.data {
@@ -297,12 +312,14 @@ func (q *QueryBuilder[T]) Count() (int64, error) {
 func (q *QueryBuilder[T]) Update(obj T) error {
 	q.store.mu.Lock()
 	defer q.store.mu.Unlock()
+	q.searchText()
 
 	found := false
 	for id, existingObj := range q.store.data {
 		if q.match(existingObj) {
 			found = true
 			q.store.data[id] = obj
+			q.store.indexRow(id, obj)
 		}
 	}
 
@@ -320,12 +337,14 @@ func (q *QueryBuilder[T]) Upsert(obj T) error {
 	defer q.store.mu.Unlock()
 
 	q.store.stateManager.MarkChanged()
+	q.searchText()
 
 	found := false
 	for id, existingObj := range q.store.data {
 		if q.match(existingObj) {
 			found = true
 			q.store.data[id] = obj
+			q.store.indexRow(id, obj)
 		}
 	}
 
@@ -339,6 +358,7 @@ func (q *QueryBuilder[T]) Upsert(obj T) error {
 func (q *QueryBuilder[T]) UpdateFields(fields map[string]interface{}) error {
 	q.store.mu.Lock()
 	defer q.store.mu.Unlock()
+	q.searchText()
 
 	for id, obj := range q.store.data {
 		if q.match(obj) {
@@ -346,6 +366,7 @@ func (q *QueryBuilder[T]) UpdateFields(fields map[string]interface{}) error {
 				setField(&obj, field, value)
 			}
 			q.store.data[id] = obj
+			q.store.indexRow(id, obj)
 		}
 	}
 	q.store.stateManager.MarkChanged()
@@ -355,10 +376,12 @@ func (q *QueryBuilder[T]) UpdateFields(fields map[string]interface{}) error {
 func (q *QueryBuilder[T]) Delete() error {
 	q.store.mu.Lock()
 	defer q.store.mu.Unlock()
+	q.searchText()
 
 	for id, obj := range q.store.data {
 		if q.match(obj) {
 			delete(q.store.data, id)
+			q.store.unindexRow(id)
 		}
 	}
 	q.store.stateManager.MarkChanged()
@@ -366,7 +389,7 @@ func (q *QueryBuilder[T]) Delete() error {
 }
 
 func (q *QueryBuilder[T]) match(obj T) bool {
-	for _, cond := range q.conditions {
+	for i, cond := range q.conditions {
 		if cond.Equal != nil {
 			fieldValue := getField(obj, cond.Equal.FieldName)
 
@@ -398,6 +421,10 @@ func (q *QueryBuilder[T]) match(obj T) bool {
 					return false
 				}
 			}
+		} else if cond.Match != nil {
+			if _, ok := q.matches[i][obj.GetId()]; !ok {
+				return false
+			}
 		} else if cond.All != nil {
 			continue
 		}
@@ -405,6 +432,37 @@ func (q *QueryBuilder[T]) match(obj T) bool {
 	return true
 }
 
+// searchText evaluates the full text conditions of the query using the text indexes.
+func (q *QueryBuilder[T]) searchText() {
+	q.matches = map[int]map[string]struct{}{}
+	for i, cond := range q.conditions {
+		if cond.Match != nil {
+			q.matches[i] = q.store.searchText(cond.Match)
+		}
+	}
+}
+
+// candidates returns the rows that can match the query.
+func (q *QueryBuilder[T]) candidates() map[string]T {
+	var smallest map[string]struct{}
+	for _, ids := range q.matches {
+		if smallest == nil || len(ids) < len(smallest) {
+			smallest = ids
+		}
+	}
+	if smallest == nil {
+		return q.store.data
+	}
+
+	ret := make(map[string]T, len(smallest))
+	for id := range smallest {
+		if obj, ok := q.store.data[id]; ok {
+			ret[id] = obj
+		}
+	}
+	return ret
+}
+
 func fixFieldName(s string) string {
 	parts := strings.Split(s, ".")
 	for i := range parts {
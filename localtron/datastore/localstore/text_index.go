@@ -0,0 +1,173 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package localstore
+
+import (
+	"strings"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+)
+
+/*
+textIndex is an inverted index of the words of a text field,
+used to evaluate full text match conditions without scanning every row.
+*/
+type textIndex struct {
+	// postings are the ids of the rows containing a word
+	postings map[string]map[string]struct{}
+	// words are the distinct words of a row by row id
+	words map[string][]string
+}
+
+func newTextIndex() *textIndex {
+	return &textIndex{
+		postings: map[string]map[string]struct{}{},
+		words:    map[string][]string{},
+	}
+}
+
+func (ti *textIndex) add(id string, text string) {
+	ti.remove(id)
+
+	seen := map[string]bool{}
+	words := []string{}
+	for _, word := range datastore.Words(text) {
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+
+		ids, ok := ti.postings[word]
+		if !ok {
+			ids = map[string]struct{}{}
+			ti.postings[word] = ids
+		}
+		ids[id] = struct{}{}
+	}
+	if len(words) > 0 {
+		ti.words[id] = words
+	}
+}
+
+func (ti *textIndex) remove(id string) {
+	for _, word := range ti.words[id] {
+		ids := ti.postings[word]
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(ti.postings, word)
+		}
+	}
+	delete(ti.words, id)
+}
+
+/*
+search returns the ids of the rows in which every word of the query
+is the start of a word. A query without words matches nothing.
+*/
+func (ti *textIndex) search(query string) map[string]struct{} {
+	var result map[string]struct{}
+
+	for _, term := range datastore.Words(query) {
+		termIds := map[string]struct{}{}
+		for word, ids := range ti.postings {
+			if !strings.HasPrefix(word, term) {
+				continue
+			}
+			for id := range ids {
+				if result == nil {
+					termIds[id] = struct{}{}
+					continue
+				}
+				if _, ok := result[id]; ok {
+					termIds[id] = struct{}{}
+				}
+			}
+		}
+		result = termIds
+		if len(result) == 0 {
+			break
+		}
+	}
+
+	if result == nil {
+		return map[string]struct{}{}
+	}
+	return result
+}
+
+// textOf returns the indexable text of a field of a row.
+func textOf[T any](obj T, field string) string {
+	switch v := getField(obj, field).(type) {
+	case string:
+		return v
+	case *string:
+		if v != nil {
+			return *v
+		}
+	}
+	return ""
+}
+
+/*
+textIndex returns the index of a field, building it on first use.
+The caller must hold the store lock, for reading at least.
+*/
+func (s *LocalStore[T]) textIndex(field string) *textIndex {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	if s.textIndexes == nil {
+		s.textIndexes = map[string]*textIndex{}
+	}
+
+	index, ok := s.textIndexes[field]
+	if ok {
+		return index
+	}
+
+	index = newTextIndex()
+	for id, obj := range s.data {
+		index.add(id, textOf(obj, field))
+	}
+	s.textIndexes[field] = index
+
+	return index
+}
+
+func (s *LocalStore[T]) searchText(cond *datastore.MatchCondition) map[string]struct{} {
+	index := s.textIndex(cond.FieldName)
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	return index.search(cond.Query)
+}
+
+// indexRow updates the text indexes already built after a row is saved.
+func (s *LocalStore[T]) indexRow(id string, obj T) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	for field, index := range s.textIndexes {
+		index.add(id, textOf(obj, field))
+	}
+}
+
+// unindexRow updates the text indexes already built after a row is deleted.
+func (s *LocalStore[T]) unindexRow(id string) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	for _, index := range s.textIndexes {
+		index.remove(id)
+	}
+}
@@ -12,7 +12,12 @@ package sqlstore
 
 import (
 	"fmt"
+	"log/slog"
 	"reflect"
+	"strings"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	"github.com/singulatron/singulatron/localtron/logger"
 )
 
 func (s *SQLStore[T]) placeholder(counter int) string {
@@ -59,6 +64,32 @@ func (q *SQLQueryBuilder[T]) buildConditions(start ...int) ([]string, []interfac
 			paramCounter++
 		}
 
+		if cond.Match != nil {
+			if q.store.driverName != DriverPostGRES {
+				return nil, nil, fmt.Errorf("full text match is not supported by '%v'", q.store.driverName)
+			}
+
+			words := datastore.Words(cond.Match.Query)
+			if len(words) == 0 {
+				conditions = append(conditions, "FALSE")
+				continue
+			}
+
+			fieldName := q.store.fieldName(cond.Match.FieldName)
+			q.store.ensureTextIndex(fieldName)
+
+			// every word of the query is a prefix, eg. "hel:* & wor:*"
+			for i := range words {
+				words[i] += ":*"
+			}
+			conditions = append(conditions, fmt.Sprintf("to_tsvector('simple', %s) @@ to_tsquery('simple', %s)",
+				fieldName,
+				q.store.placeholder(paramCounter),
+			))
+			params = append(params, strings.Join(words, " & "))
+			paramCounter++
+		}
+
 		if err != nil {
 			return nil, nil, err
 		}
@@ -81,3 +112,27 @@ func (q *SQLQueryBuilder[T]) buildConditions(start ...int) ([]string, []interfac
 
 	return conditions, params, nil
 }
+
+/*
+ensureTextIndex creates a GIN index on the tsvector of a column
+the first time it is used in a full text condition.
+*/
+func (s *SQLStore[T]) ensureTextIndex(fieldName string) {
+	if strings.ContainsAny(fieldName, "-'\"") {
+		// only top level columns are indexed
+		return
+	}
+	if _, done := s.textIndexes.LoadOrStore(fieldName, true); done {
+		return
+	}
+
+	_, err := s.db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %v_%v_fts ON %v USING GIN (to_tsvector('simple', %v));",
+		s.tableName,
+		fieldName,
+		s.tableName,
+		fieldName,
+	))
+	if err != nil {
+		logger.Debug("Error creating text index", slog.Any("error", err))
+	}
+}
@@ -55,6 +55,8 @@ type SQLStore[T datastore.Row] struct {
 	tableName        string
 	fieldTypes       map[string]reflect.Type
 	idFieldName      string
+	// textIndexes are the columns with a full text index
+	textIndexes sync.Map
 }
 
 func NewSQLStore[T datastore.Row](driverName, connStr string, tableName string, debug bool) (*SQLStore[T], error) {
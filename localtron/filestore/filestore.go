@@ -0,0 +1,34 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package filestore
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	ErrNotFound = errors.New("file not found")
+)
+
+/*
+FileStore keeps the content of files outside of the datastore.
+Files are addressed by the key returned when they are saved,
+so the same content saved twice is only kept once.
+*/
+type FileStore interface {
+	/* Save the content read from r. Returns the key of the content and its size in bytes. */
+	Put(r io.Reader) (key string, size int64, err error)
+	/* Open the content of a key. Returns ErrNotFound if there is no such content. */
+	Open(key string) (io.ReadCloser, error)
+	/* Delete the content of a key. Deleting missing content is not an error. */
+	Delete(key string) error
+}
@@ -0,0 +1,116 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package localfilestore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/singulatron/singulatron/localtron/filestore"
+)
+
+var keyPattern = regexp.MustCompile("^[0-9a-f]{64}$")
+
+/*
+LocalFileStore saves files in a folder by the SHA-256 hash of their content.
+Files are sharded into subfolders by the first two characters of the hash.
+*/
+type LocalFileStore struct {
+	folder string
+}
+
+func NewLocalFileStore(folder string) *LocalFileStore {
+	return &LocalFileStore{
+		folder: folder,
+	}
+}
+
+func (s *LocalFileStore) Put(r io.Reader) (string, int64, error) {
+	err := os.MkdirAll(s.folder, 0755)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// write to a temporary file first as the key is only known once everything is read
+	tmp, err := os.CreateTemp(s.folder, "upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err != nil {
+		return "", 0, err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return "", 0, err
+	}
+
+	key := hex.EncodeToString(hash.Sum(nil))
+	filePath := s.path(key)
+
+	if _, err := os.Stat(filePath); err == nil {
+		return key, size, nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return "", 0, err
+	}
+
+	err = os.Rename(tmp.Name(), filePath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return key, size, nil
+}
+
+func (s *LocalFileStore) Open(key string) (io.ReadCloser, error) {
+	if !keyPattern.MatchString(key) {
+		return nil, fmt.Errorf("%w: invalid key '%v'", filestore.ErrNotFound, key)
+	}
+
+	file, err := os.Open(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: '%v'", filestore.ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (s *LocalFileStore) Delete(key string) error {
+	if !keyPattern.MatchString(key) {
+		return nil
+	}
+
+	err := os.Remove(s.path(key))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalFileStore) path(key string) string {
+	return filepath.Join(s.folder, key[:2], key)
+}
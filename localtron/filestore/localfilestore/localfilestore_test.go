@@ -0,0 +1,50 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package localfilestore
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singulatron/singulatron/localtron/filestore"
+)
+
+func TestLocalFileStore(t *testing.T) {
+	store := NewLocalFileStore(t.TempDir())
+
+	key, size, err := store.Put(strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", key)
+	assert.Equal(t, int64(5), size)
+
+	sameKey, _, err := store.Put(strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, key, sameKey)
+
+	file, err := store.Open(key)
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	assert.Equal(t, "hello", string(content))
+
+	require.NoError(t, store.Delete(key))
+	_, err = store.Open(key)
+	assert.True(t, errors.Is(err, filestore.ErrNotFound))
+
+	_, err = store.Open("../../etc/passwd")
+	assert.True(t, errors.Is(err, filestore.ErrNotFound))
+}
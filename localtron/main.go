@@ -46,6 +46,18 @@ import (
 	promptservice "github.com/singulatron/singulatron/localtron/services/prompt"
 	promptendpoints "github.com/singulatron/singulatron/localtron/services/prompt/endpoints"
 
+	embeddingservice "github.com/singulatron/singulatron/localtron/services/embedding"
+	embeddingendpoints "github.com/singulatron/singulatron/localtron/services/embedding/endpoints"
+
+	documentservice "github.com/singulatron/singulatron/localtron/services/document"
+	documentendpoints "github.com/singulatron/singulatron/localtron/services/document/endpoints"
+
+	toolservice "github.com/singulatron/singulatron/localtron/services/tool"
+	toolendpoints "github.com/singulatron/singulatron/localtron/services/tool/endpoints"
+
+	openaiservice "github.com/singulatron/singulatron/localtron/services/openai"
+	openaiendpoints "github.com/singulatron/singulatron/localtron/services/openai/endpoints"
+
 	firehoseservice "github.com/singulatron/singulatron/localtron/services/firehose"
 	firehoseendpoints "github.com/singulatron/singulatron/localtron/services/firehose/endpoints"
 	firehosetypes "github.com/singulatron/singulatron/localtron/services/firehose/types"
@@ -183,7 +195,13 @@ func main() {
 		dockerendpoints.Info(w, r, userService, dockerService)
 	}))
 
-	modelService, err := modelservice.NewModelService(downloadService, userService, configService, dockerService)
+	modelService, err := modelservice.NewModelService(
+		downloadService,
+		userService,
+		configService,
+		dockerService,
+		firehoseService,
+	)
 	if err != nil {
 		logger.Error("Model service creation failed", slog.String("error", err.Error()))
 		os.Exit(1)
@@ -198,6 +216,9 @@ func main() {
 	router.HandleFunc("/model/start", appl(func(w http.ResponseWriter, r *http.Request) {
 		modelendpoints.Start(w, r, userService, modelService)
 	}))
+	router.HandleFunc("/model/stop", appl(func(w http.ResponseWriter, r *http.Request) {
+		modelendpoints.Stop(w, r, userService, modelService)
+	}))
 	router.HandleFunc("/model/make-default", appl(func(w http.ResponseWriter, r *http.Request) {
 		modelendpoints.MakeDefault(w, r, userService, modelService)
 	}))
@@ -228,10 +249,63 @@ func main() {
 		appendpoints.LoggingStatus(w, r, appService)
 	}))
 
+	embeddingService, err := embeddingservice.NewEmbeddingService(
+		configService,
+		userService,
+		modelService,
+	)
+	if err != nil {
+		logger.Error("Embedding service creation failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	router.HandleFunc("/embedding/embed", appl(func(w http.ResponseWriter, r *http.Request) {
+		embeddingendpoints.Embed(w, r, userService, embeddingService)
+	}))
+
+	router.HandleFunc("/embedding/search", appl(func(w http.ResponseWriter, r *http.Request) {
+		embeddingendpoints.Search(w, r, userService, embeddingService)
+	}))
+
+	documentService, err := documentservice.NewDocumentService(
+		configService,
+		userService,
+		embeddingService,
+	)
+	if err != nil {
+		logger.Error("Document service creation failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	router.HandleFunc("/document/collection/save", appl(func(w http.ResponseWriter, r *http.Request) {
+		documentendpoints.SaveCollection(w, r, userService, documentService)
+	}))
+
+	router.HandleFunc("/document/collection/list", appl(func(w http.ResponseWriter, r *http.Request) {
+		documentendpoints.ListCollections(w, r, userService, documentService)
+	}))
+
+	router.HandleFunc("/document/collection/delete", appl(func(w http.ResponseWriter, r *http.Request) {
+		documentendpoints.DeleteCollection(w, r, userService, documentService)
+	}))
+
+	router.HandleFunc("/document/add", appl(func(w http.ResponseWriter, r *http.Request) {
+		documentendpoints.Add(w, r, userService, documentService)
+	}))
+
+	router.HandleFunc("/document/list", appl(func(w http.ResponseWriter, r *http.Request) {
+		documentendpoints.List(w, r, userService, documentService)
+	}))
+
+	router.HandleFunc("/document/delete", appl(func(w http.ResponseWriter, r *http.Request) {
+		documentendpoints.Delete(w, r, userService, documentService)
+	}))
+
 	chatService, err := chatservice.NewChatService(
 		configService,
 		firehoseService,
 		userService,
+		documentService,
 	)
 	if err != nil {
 		logger.Error("Chat service creation failed", slog.String("error", err.Error()))
@@ -246,10 +320,22 @@ func main() {
 		chatendpoints.DeleteMessage(w, r, userService, chatService)
 	}))
 
+	router.HandleFunc("/chat/message/select", appl(func(w http.ResponseWriter, r *http.Request) {
+		chatendpoints.SelectMessage(w, r, userService, chatService)
+	}))
+
 	router.HandleFunc("/chat/messages", appl(func(w http.ResponseWriter, r *http.Request) {
 		chatendpoints.GetMessages(w, r, userService, chatService)
 	}))
 
+	router.HandleFunc("/chat/asset/", appl(func(w http.ResponseWriter, r *http.Request) {
+		chatendpoints.GetAsset(w, r, userService, chatService)
+	}))
+
+	router.HandleFunc("/chat/search", appl(func(w http.ResponseWriter, r *http.Request) {
+		chatendpoints.Search(w, r, userService, chatService)
+	}))
+
 	router.HandleFunc("/chat/thread/add", appl(func(w http.ResponseWriter, r *http.Request) {
 		chatendpoints.AddThread(w, r, userService, chatService)
 	}))
@@ -258,6 +344,14 @@ func main() {
 		chatendpoints.DeleteThread(w, r, userService, chatService)
 	}))
 
+	router.HandleFunc("/chat/thread/export", appl(func(w http.ResponseWriter, r *http.Request) {
+		chatendpoints.ExportThread(w, r, userService, chatService)
+	}))
+
+	router.HandleFunc("/chat/thread/import", appl(func(w http.ResponseWriter, r *http.Request) {
+		chatendpoints.ImportThread(w, r, userService, chatService)
+	}))
+
 	router.HandleFunc("/chat/threads", appl(func(w http.ResponseWriter, r *http.Request) {
 		chatendpoints.GetThreads(w, r, userService, chatService)
 	}))
@@ -270,12 +364,63 @@ func main() {
 		chatendpoints.UpdateThread(w, r, userService, chatService)
 	}))
 
+	genericService, err := genericservice.NewGenericService(
+		configService,
+		firehoseService,
+		userService,
+	)
+	if err != nil {
+		logger.Error("Generic service creation failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	router.HandleFunc("/generic/create", appl(func(w http.ResponseWriter, r *http.Request) {
+		genericendpoints.Create(w, r, userService, genericService)
+	}))
+	router.HandleFunc("/generic/update", appl(func(w http.ResponseWriter, r *http.Request) {
+		genericendpoints.Update(w, r, userService, genericService)
+	}))
+	router.HandleFunc("/generic/delete", appl(func(w http.ResponseWriter, r *http.Request) {
+		genericendpoints.Delete(w, r, userService, genericService)
+	}))
+	router.HandleFunc("/generic/find", appl(func(w http.ResponseWriter, r *http.Request) {
+		genericendpoints.Find(w, r, userService, genericService)
+	}))
+	router.HandleFunc("/generic/upsert", appl(func(w http.ResponseWriter, r *http.Request) {
+		genericendpoints.Upsert(w, r, userService, genericService)
+	}))
+
+	toolService, err := toolservice.NewToolService(
+		configService,
+		userService,
+		chatService,
+		genericService,
+	)
+	if err != nil {
+		logger.Error("Tool service creation failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	router.HandleFunc("/tool/save", appl(func(w http.ResponseWriter, r *http.Request) {
+		toolendpoints.Save(w, r, userService, toolService)
+	}))
+
+	router.HandleFunc("/tool/list", appl(func(w http.ResponseWriter, r *http.Request) {
+		toolendpoints.List(w, r, userService, toolService)
+	}))
+
+	router.HandleFunc("/tool/delete", appl(func(w http.ResponseWriter, r *http.Request) {
+		toolendpoints.Delete(w, r, userService, toolService)
+	}))
+
 	promptService, err := promptservice.NewPromptService(
 		configService,
 		userService,
 		modelService,
 		chatService,
 		firehoseService,
+		toolService,
+		documentService,
 	)
 	if err != nil {
 		logger.Error("Prompt service creation failed", slog.String("error", err.Error()))
@@ -290,6 +435,14 @@ func main() {
 		promptendpoints.Remove(w, r, userService, promptService)
 	}))
 
+	router.HandleFunc("/prompt/cancel", appl(func(w http.ResponseWriter, r *http.Request) {
+		promptendpoints.Cancel(w, r, userService, promptService)
+	}))
+
+	router.HandleFunc("/prompt/retry", appl(func(w http.ResponseWriter, r *http.Request) {
+		promptendpoints.Retry(w, r, userService, promptService)
+	}))
+
 	router.HandleFunc("/prompt/subscribe", appl(func(w http.ResponseWriter, r *http.Request) {
 		promptendpoints.Subscribe(w, r, userService, promptService)
 	}))
@@ -298,6 +451,59 @@ func main() {
 		promptendpoints.List(w, r, userService, promptService)
 	}))
 
+	router.HandleFunc("/prompt/stats", appl(func(w http.ResponseWriter, r *http.Request) {
+		promptendpoints.Stats(w, r, userService, promptService)
+	}))
+
+	router.HandleFunc("/prompt/quota/save", appl(func(w http.ResponseWriter, r *http.Request) {
+		promptendpoints.SaveQuota(w, r, userService, promptService)
+	}))
+
+	router.HandleFunc("/prompt/quota/list", appl(func(w http.ResponseWriter, r *http.Request) {
+		promptendpoints.ListQuotas(w, r, userService, promptService)
+	}))
+
+	router.HandleFunc("/prompt/quota/delete", appl(func(w http.ResponseWriter, r *http.Request) {
+		promptendpoints.DeleteQuota(w, r, userService, promptService)
+	}))
+
+	router.HandleFunc("/prompt/template/save", appl(func(w http.ResponseWriter, r *http.Request) {
+		promptendpoints.SaveTemplate(w, r, userService, promptService)
+	}))
+
+	router.HandleFunc("/prompt/template/list", appl(func(w http.ResponseWriter, r *http.Request) {
+		promptendpoints.ListTemplates(w, r, userService, promptService)
+	}))
+
+	router.HandleFunc("/prompt/template/delete", appl(func(w http.ResponseWriter, r *http.Request) {
+		promptendpoints.DeleteTemplate(w, r, userService, promptService)
+	}))
+
+	openaiService, err := openaiservice.NewOpenAIService(
+		configService,
+		userService,
+		modelService,
+		chatService,
+		promptService,
+		firehoseService,
+	)
+	if err != nil {
+		logger.Error("OpenAI service creation failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	router.HandleFunc("/v1/models", appl(func(w http.ResponseWriter, r *http.Request) {
+		openaiendpoints.Models(w, r, userService, openaiService)
+	}))
+
+	router.HandleFunc("/v1/chat/completions", appl(func(w http.ResponseWriter, r *http.Request) {
+		openaiendpoints.ChatCompletions(w, r, userService, openaiService)
+	}))
+
+	router.HandleFunc("/v1/completions", appl(func(w http.ResponseWriter, r *http.Request) {
+		openaiendpoints.Completions(w, r, userService, openaiService)
+	}))
+
 	router.HandleFunc("/user/login", appl(func(w http.ResponseWriter, r *http.Request) {
 		userendpoints.Login(w, r, userService)
 	}))
@@ -332,32 +538,6 @@ func main() {
 		userendpoints.SetRolePermissions(w, r, userService)
 	}))
 
-	genericService, err := genericservice.NewGenericService(
-		configService,
-		firehoseService,
-		userService,
-	)
-	if err != nil {
-		logger.Error("Generic service creation failed", slog.String("error", err.Error()))
-		os.Exit(1)
-	}
-
-	router.HandleFunc("/generic/create", appl(func(w http.ResponseWriter, r *http.Request) {
-		genericendpoints.Create(w, r, userService, genericService)
-	}))
-	router.HandleFunc("/generic/update", appl(func(w http.ResponseWriter, r *http.Request) {
-		genericendpoints.Update(w, r, userService, genericService)
-	}))
-	router.HandleFunc("/generic/delete", appl(func(w http.ResponseWriter, r *http.Request) {
-		genericendpoints.Delete(w, r, userService, genericService)
-	}))
-	router.HandleFunc("/generic/find", appl(func(w http.ResponseWriter, r *http.Request) {
-		genericendpoints.Find(w, r, userService, genericService)
-	}))
-	router.HandleFunc("/generic/upsert", appl(func(w http.ResponseWriter, r *http.Request) {
-		genericendpoints.Upsert(w, r, userService, genericService)
-	}))
-
 	srv := &http.Server{
 		Handler: router,
 	}
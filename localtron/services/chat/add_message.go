@@ -22,6 +22,11 @@ import (
 	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
 )
 
+/*
+AddMessage saves a message.
+Messages without a parent follow the last message of the active branch
+of the thread, or keep their parent when they are saved again.
+*/
 func (a *ChatService) AddMessage(chatMessage *chattypes.Message) error {
 	if chatMessage.ThreadId == "" {
 		return errors.New("empty chat message thread id")
@@ -44,6 +49,11 @@ func (a *ChatService) AddMessage(chatMessage *chattypes.Message) error {
 		return errors.New("thread does not exist")
 	}
 
+	err = a.setParent(chatMessage)
+	if err != nil {
+		return err
+	}
+
 	logger.Info("Saving chat message",
 		slog.String("messageId", chatMessage.Id),
 	)
@@ -56,3 +66,44 @@ func (a *ChatService) AddMessage(chatMessage *chattypes.Message) error {
 		datastore.Equal("id", chatMessage.Id),
 	).Upsert(chatMessage)
 }
+
+func (a *ChatService) setParent(chatMessage *chattypes.Message) error {
+	if chatMessage.ParentId == chatMessage.ThreadId {
+		return nil
+	}
+
+	if chatMessage.ParentId != "" {
+		_, found, err := a.messagesStore.Query(
+			datastore.Id(chatMessage.ParentId),
+			datastore.Equal("threadId", chatMessage.ThreadId),
+		).FindOne()
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.New("parent message does not exist")
+		}
+		return nil
+	}
+
+	messages, err := a.messagesStore.Query(
+		datastore.Equal("threadId", chatMessage.ThreadId),
+	).Find()
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		if message.Id == chatMessage.Id {
+			chatMessage.ParentId = message.ParentId
+			return nil
+		}
+	}
+
+	chatMessage.ParentId = newMessageTree(messages).leaf()
+	if chatMessage.ParentId == "" {
+		chatMessage.ParentId = chatMessage.ThreadId
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	configservice "github.com/singulatron/singulatron/localtron/services/config"
+	firehoseservice "github.com/singulatron/singulatron/localtron/services/firehose"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func TestAssets(t *testing.T) {
+	cs, err := configservice.NewConfigService()
+	require.NoError(t, err)
+	us, err := userservice.NewUserService(cs)
+	require.NoError(t, err)
+	fs, err := firehoseservice.NewFirehoseService(us)
+	require.NoError(t, err)
+	as, err := chatservice.NewChatService(cs, fs, us, nil)
+	require.NoError(t, err)
+
+	readAsset := func(t *testing.T, id string) (*chattypes.Asset, string) {
+		asset, content, err := as.OpenAsset(id)
+		require.NoError(t, err)
+		defer content.Close()
+		bs, err := io.ReadAll(content)
+		require.NoError(t, err)
+		return asset, string(bs)
+	}
+
+	t.Run("save and open", func(t *testing.T) {
+		asset, err := as.SaveAsset(strings.NewReader("fake png"), "image/png")
+		require.NoError(t, err)
+		require.Equal(t, "/chat/asset/"+asset.Id, asset.Url)
+		require.Equal(t, int64(8), asset.Size)
+		require.Empty(t, asset.Content)
+
+		opened, content := readAsset(t, asset.Id)
+		require.Equal(t, "fake png", content)
+		require.Equal(t, "image/png", opened.Type)
+	})
+
+	t.Run("inline content is moved to the file store", func(t *testing.T) {
+		id := uuid.New().String()
+		err := as.UpsertAssets([]*chattypes.Asset{{
+			Id:      id,
+			Content: base64.StdEncoding.EncodeToString([]byte("inline image")),
+		}})
+		require.NoError(t, err)
+
+		assets, err := as.GetAssets([]string{id})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(assets))
+		require.Empty(t, assets[0].Content)
+		require.NotEmpty(t, assets[0].StorageKey)
+
+		_, content := readAsset(t, id)
+		require.Equal(t, "inline image", content)
+	})
+
+	t.Run("missing asset", func(t *testing.T) {
+		_, _, err := as.OpenAsset(uuid.New().String())
+		require.True(t, errors.Is(err, chatservice.ErrAssetNotFound))
+	})
+}
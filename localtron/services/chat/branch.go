@@ -0,0 +1,151 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice
+
+import (
+	"sort"
+	"time"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+/*
+messageTree arranges the messages of a thread by the message they follow.
+Summaries are kept apart from the alternatives as they annotate
+the message they follow instead of continuing the conversation.
+*/
+type messageTree struct {
+	// children are the messages following a message by its id, "" is the start of the thread
+	children map[string][]*chattypes.Message
+	// summaries are the summaries following a message by its id
+	summaries map[string][]*chattypes.Message
+	parents   map[string]string
+}
+
+func newMessageTree(messages []*chattypes.Message) *messageTree {
+	sorted := append([]*chattypes.Message{}, messages...)
+	sort.Stable(chattypes.ByTime(sorted))
+
+	ids := map[string]bool{}
+	for _, message := range sorted {
+		ids[message.Id] = true
+	}
+
+	tree := &messageTree{
+		children:  map[string][]*chattypes.Message{},
+		summaries: map[string][]*chattypes.Message{},
+		parents:   map[string]string{},
+	}
+
+	// previous and previousAny are the ids of the messages created last,
+	// the former not counting summaries
+	previous, previousAny := "", ""
+	for _, message := range sorted {
+		parent := message.ParentId
+		summary := len(message.SummaryOf) > 0
+
+		switch {
+		case parent == message.ThreadId:
+			parent = ""
+		case parent == "" || !ids[parent]:
+			// saved before branching or the parent is gone
+			parent = previous
+			if summary {
+				parent = previousAny
+			}
+		}
+
+		tree.parents[message.Id] = parent
+		if summary {
+			tree.summaries[parent] = append(tree.summaries[parent], message)
+		} else {
+			tree.children[parent] = append(tree.children[parent], message)
+			previous = message.Id
+		}
+		previousAny = message.Id
+	}
+
+	return tree
+}
+
+/*
+branch returns the messages of the active branch in order and the ids of
+the alternatives of its messages that have any.
+The active branch follows the alternative created or selected last.
+*/
+func (t *messageTree) branch() ([]*chattypes.Message, map[string][]string) {
+	messages := []*chattypes.Message{}
+	alternatives := map[string][]string{}
+
+	parent := ""
+	for {
+		children := t.children[parent]
+		if len(children) == 0 {
+			break
+		}
+
+		message := children[0]
+		for _, child := range children[1:] {
+			if !activeAt(child).Before(activeAt(message)) {
+				message = child
+			}
+		}
+
+		if len(children) > 1 {
+			ids := []string{}
+			for _, child := range children {
+				ids = append(ids, child.Id)
+			}
+			alternatives[message.Id] = ids
+		}
+
+		messages = append(messages, message)
+		messages = t.appendSummaries(messages, message.Id)
+		parent = message.Id
+	}
+
+	return messages, alternatives
+}
+
+func (t *messageTree) appendSummaries(messages []*chattypes.Message, messageId string) []*chattypes.Message {
+	for _, summary := range t.summaries[messageId] {
+		messages = append(messages, summary)
+		messages = t.appendSummaries(messages, summary.Id)
+	}
+	return messages
+}
+
+// leaf returns the id of the last message of the active branch, "" for empty threads
+func (t *messageTree) leaf() string {
+	messages, _ := t.branch()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if len(messages[i].SummaryOf) == 0 {
+			return messages[i].Id
+		}
+	}
+	return ""
+}
+
+// path returns the id of a message and the ids of the messages it follows
+func (t *messageTree) path(messageId string) []string {
+	ids := []string{}
+	for id := messageId; id != ""; id = t.parents[id] {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func activeAt(message *chattypes.Message) time.Time {
+	if message.SelectedAt.After(message.CreatedAt) {
+		return message.SelectedAt
+	}
+	return message.CreatedAt
+}
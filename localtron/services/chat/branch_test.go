@@ -0,0 +1,100 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	configservice "github.com/singulatron/singulatron/localtron/services/config"
+	firehoseservice "github.com/singulatron/singulatron/localtron/services/firehose"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func TestBranches(t *testing.T) {
+	cs, err := configservice.NewConfigService()
+	require.NoError(t, err)
+	us, err := userservice.NewUserService(cs)
+	require.NoError(t, err)
+	fs, err := firehoseservice.NewFirehoseService(us)
+	require.NoError(t, err)
+	as, err := chatservice.NewChatService(cs, fs, us, nil)
+	require.NoError(t, err)
+
+	thread, err := as.AddThread(&chattypes.Thread{
+		Id:      uuid.New().String(),
+		Title:   "Branches",
+		UserIds: []string{"usr-1"},
+	})
+	require.NoError(t, err)
+
+	// messages are created in the past so selecting them is newer
+	start := time.Now().Add(-time.Minute)
+	add := func(content string, parentId string, offset int) *chattypes.Message {
+		message := &chattypes.Message{
+			Id:        uuid.New().String(),
+			ThreadId:  thread.Id,
+			ParentId:  parentId,
+			Content:   content,
+			CreatedAt: start.Add(time.Duration(offset) * time.Second),
+		}
+		require.NoError(t, as.AddMessage(message))
+		return message
+	}
+	contents := func(messages []*chattypes.Message) []string {
+		ret := []string{}
+		for _, message := range messages {
+			ret = append(ret, message.Content)
+		}
+		return ret
+	}
+
+	question := add("What's a banana?", "", 0)
+	assert.Equal(t, thread.Id, question.ParentId)
+	answer := add("A fruit.", "", 1)
+	assert.Equal(t, question.Id, answer.ParentId)
+
+	t.Run("editing starts an alternative branch", func(t *testing.T) {
+		edited := add("What's an apple?", thread.Id, 2)
+		add("Also a fruit.", "", 3)
+
+		messages, alternatives, err := as.GetMessages(thread.Id)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"What's an apple?", "Also a fruit."}, contents(messages))
+		assert.Equal(t, []string{question.Id, edited.Id}, alternatives[edited.Id])
+	})
+
+	t.Run("selecting a message activates its branch", func(t *testing.T) {
+		require.NoError(t, as.SelectMessage(answer.Id))
+
+		messages, alternatives, err := as.GetMessages(thread.Id)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"What's a banana?", "A fruit."}, contents(messages))
+		assert.Equal(t, 2, len(alternatives[question.Id]))
+
+		followUp := add("Is it yellow?", "", 4)
+		assert.Equal(t, answer.Id, followUp.ParentId)
+	})
+
+	t.Run("deleting a message keeps the messages following it", func(t *testing.T) {
+		require.NoError(t, as.DeleteMessage(answer.Id))
+
+		messages, _, err := as.GetMessages(thread.Id)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"What's a banana?", "Is it yellow?"}, contents(messages))
+	})
+}
@@ -12,9 +12,11 @@ package chatservice
 
 import (
 	"github.com/singulatron/singulatron/localtron/datastore"
+	"github.com/singulatron/singulatron/localtron/filestore"
 
 	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
 	configservice "github.com/singulatron/singulatron/localtron/services/config"
+	documentservice "github.com/singulatron/singulatron/localtron/services/document"
 	firehoseservice "github.com/singulatron/singulatron/localtron/services/firehose"
 	userservice "github.com/singulatron/singulatron/localtron/services/user"
 
@@ -25,16 +27,19 @@ type ChatService struct {
 	configService   *configservice.ConfigService
 	userService     *userservice.UserService
 	firehoseService *firehoseservice.FirehoseService
+	documentService *documentservice.DocumentService
 
 	messagesStore datastore.DataStore[*chattypes.Message]
 	threadsStore  datastore.DataStore[*chattypes.Thread]
 	assetsStore   datastore.DataStore[*chattypes.Asset]
+	assetFiles    filestore.FileStore
 }
 
 func NewChatService(
 	cs *configservice.ConfigService,
 	fs *firehoseservice.FirehoseService,
 	userService *userservice.UserService,
+	documentService *documentservice.DocumentService,
 ) (*ChatService, error) {
 	threadsStore, err := storefactoryservice.GetStore[*chattypes.Thread]("threads")
 	if err != nil {
@@ -48,15 +53,21 @@ func NewChatService(
 	if err != nil {
 		return nil, err
 	}
+	assetFiles, err := storefactoryservice.GetFileStore("assets")
+	if err != nil {
+		return nil, err
+	}
 
 	service := &ChatService{
 		configService:   cs,
 		firehoseService: fs,
 		userService:     userService,
+		documentService: documentService,
 
 		messagesStore: messagesStore,
 		threadsStore:  threadsStore,
 		assetsStore:   assetsStore,
+		assetFiles:    assetFiles,
 	}
 
 	err = service.registerPermissions()
@@ -64,5 +75,10 @@ func NewChatService(
 		return nil, err
 	}
 
+	err = service.migrateInlineAssets()
+	if err != nil {
+		return nil, err
+	}
+
 	return service, nil
 }
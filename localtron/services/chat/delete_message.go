@@ -14,10 +14,28 @@ import (
 	"github.com/singulatron/singulatron/localtron/datastore"
 )
 
+// DeleteMessage deletes a message, the messages following it follow its parent instead
 func (a *ChatService) DeleteMessage(id string) error {
+	message, found, err := a.messagesStore.Query(
+		datastore.Id(id),
+	).FindOne()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+
+	err = a.messagesStore.Query(
+		datastore.Equal("parentId", id),
+	).UpdateFields(map[string]any{
+		"parentId": message.ParentId,
+	})
+	if err != nil {
+		return err
+	}
+
 	return a.messagesStore.Query(
 		datastore.Equal("id", id),
 	).Delete()
-
-	return nil
 }
@@ -12,6 +12,7 @@ package appendpoints
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
@@ -52,6 +53,16 @@ func AddThread(
 
 	req.Thread.UserIds = append(req.Thread.UserIds, user.Id)
 
+	err = ds.CheckThreadCollections(user.Id, req.Thread)
+	if errors.Is(err, chatservice.ErrUnauthorized) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	thread, err := ds.AddThread(req.Thread)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -0,0 +1,99 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package appendpoints
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+
+	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
+	types "github.com/singulatron/singulatron/localtron/services/chat/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func ExportThread(
+	w http.ResponseWriter,
+	r *http.Request,
+	userService *userservice.UserService,
+	ds *chatservice.ChatService,
+) {
+	err := userService.IsAuthorized(types.PermissionThreadView.Id, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	user, found, err := userService.GetUserFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if !found {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	req := types.ExportThreadRequest{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, `invalid JSON`, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	buf := &bytes.Buffer{}
+	err = ds.ExportThread(user.Id, req.ThreadId, req.Format, buf)
+	if errors.Is(err, chatservice.ErrThreadNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, chatservice.ErrInvalidExport) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	thread, _, err := ds.GetThread(req.ThreadId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", req.Format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%v%v"`,
+		fileName(thread.Title),
+		req.Format.Extension(),
+	))
+	w.Write(buf.Bytes())
+}
+
+// fileName turns a thread title into a safe file name
+func fileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, strings.TrimSpace(title))
+	name = strings.Trim(name, "-")
+	if name == "" {
+		return "thread"
+	}
+	return name
+}
@@ -0,0 +1,68 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package appendpoints
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
+	types "github.com/singulatron/singulatron/localtron/services/chat/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+/*
+GetAsset streams the content of the asset in the path, eg. /chat/asset/{id}
+*/
+func GetAsset(
+	w http.ResponseWriter,
+	r *http.Request,
+	userService *userservice.UserService,
+	ds *chatservice.ChatService,
+) {
+	err := userService.IsAuthorized(types.PermissionMessageView.Id, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/chat/asset/")
+	if id == "" || strings.Contains(id, "/") {
+		http.Error(w, "invalid asset id", http.StatusBadRequest)
+		return
+	}
+
+	asset, content, err := ds.OpenAsset(id)
+	if errors.Is(err, chatservice.ErrAssetNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	contentType := asset.Type
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if asset.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(asset.Size, 10))
+	}
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+
+	io.Copy(w, content)
+}
@@ -39,7 +39,7 @@ func GetMessages(
 	}
 	defer r.Body.Close()
 
-	messages, err := ds.GetMessages(req.ThreadId)
+	messages, alternatives, err := ds.GetMessages(req.ThreadId)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -56,8 +56,9 @@ func GetMessages(
 	}
 
 	jsonData, _ := json.Marshal(types.GetMessagesResponse{
-		Messages: messages,
-		Assets:   assets,
+		Messages:     messages,
+		Assets:       assets,
+		Alternatives: alternatives,
 	})
 	w.Write(jsonData)
 }
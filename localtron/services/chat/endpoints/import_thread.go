@@ -0,0 +1,67 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package appendpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
+	types "github.com/singulatron/singulatron/localtron/services/chat/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func ImportThread(
+	w http.ResponseWriter,
+	r *http.Request,
+	userService *userservice.UserService,
+	ds *chatservice.ChatService,
+) {
+	err := userService.IsAuthorized(types.PermissionThreadCreate.Id, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	user, found, err := userService.GetUserFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if !found {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	req := types.ImportThreadRequest{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, `invalid JSON`, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	thread, err := ds.ImportThread(user.Id, &req)
+	if errors.Is(err, chatservice.ErrInvalidImport) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jsonData, _ := json.Marshal(types.ImportThreadResponse{
+		Thread: thread,
+	})
+	w.Write(jsonData)
+}
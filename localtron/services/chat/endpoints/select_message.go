@@ -0,0 +1,55 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package appendpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
+	types "github.com/singulatron/singulatron/localtron/services/chat/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func SelectMessage(
+	w http.ResponseWriter,
+	r *http.Request,
+	userService *userservice.UserService,
+	ds *chatservice.ChatService,
+) {
+	err := userService.IsAuthorized(types.PermissionMessageEdit.Id, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	req := types.SelectMessageRequest{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, `invalid JSON`, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = ds.SelectMessage(req.MessageId)
+	if errors.Is(err, chatservice.ErrMessageNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jsonData, _ := json.Marshal(types.SelectMessageResponse{})
+	w.Write(jsonData)
+}
@@ -12,6 +12,7 @@ package appendpoints
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
@@ -31,6 +32,16 @@ func UpdateThread(
 		return
 	}
 
+	user, found, err := userService.GetUserFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if !found {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	req := types.UpdateThreadRequest{}
 	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
@@ -39,7 +50,11 @@ func UpdateThread(
 	}
 	defer r.Body.Close()
 
-	thread, err := ds.UpdateThread(req.Thread)
+	thread, err := ds.UpdateThread(user.Id, req.Thread)
+	if errors.Is(err, chatservice.ErrUnauthorized) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,139 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	configservice "github.com/singulatron/singulatron/localtron/services/config"
+	firehoseservice "github.com/singulatron/singulatron/localtron/services/firehose"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func TestExportImport(t *testing.T) {
+	cs, err := configservice.NewConfigService()
+	require.NoError(t, err)
+	us, err := userservice.NewUserService(cs)
+	require.NoError(t, err)
+	fs, err := firehoseservice.NewFirehoseService(us)
+	require.NoError(t, err)
+	as, err := chatservice.NewChatService(cs, fs, us, nil)
+	require.NoError(t, err)
+
+	userId := uuid.New().String()
+	thread, err := as.AddThread(&chattypes.Thread{
+		Title:   "Cats",
+		UserIds: []string{userId},
+	})
+	require.NoError(t, err)
+
+	asset, err := as.SaveAsset(strings.NewReader("fake png"), "image/png")
+	require.NoError(t, err)
+
+	for _, message := range []*chattypes.Message{
+		{Role: chattypes.MessageRoleUser, UserId: userId, Content: "Draw a cat"},
+		{Role: chattypes.MessageRoleAssistant, Content: "Sure, here is your image", AssetIds: []string{asset.Id}},
+	} {
+		message.ThreadId = thread.Id
+		require.NoError(t, as.AddMessage(message))
+	}
+
+	export := func(t *testing.T, format chattypes.ExportFormat) []byte {
+		buf := &bytes.Buffer{}
+		err := as.ExportThread(userId, thread.Id, format, buf)
+		require.NoError(t, err)
+		return buf.Bytes()
+	}
+
+	t.Run("only the owner can export", func(t *testing.T) {
+		err := as.ExportThread(uuid.New().String(), thread.Id, chattypes.ExportFormatJSON, &bytes.Buffer{})
+		require.True(t, errors.Is(err, chatservice.ErrThreadNotFound))
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		markdown := string(export(t, chattypes.ExportFormatMarkdown))
+		require.True(t, strings.HasPrefix(markdown, "# Cats\n"))
+		require.Contains(t, markdown, "### User\n\nDraw a cat\n")
+		require.Contains(t, markdown, "(data:image/png;base64,ZmFrZSBwbmc=)")
+	})
+
+	t.Run("json bundle round trip", func(t *testing.T) {
+		bundle := chattypes.ThreadBundle{}
+		require.NoError(t, json.Unmarshal(export(t, chattypes.ExportFormatJSON), &bundle))
+		require.Equal(t, 2, len(bundle.Messages))
+		require.Equal(t, 1, len(bundle.Assets))
+
+		importerId := uuid.New().String()
+		imported, err := as.ImportThread(importerId, &chattypes.ImportThreadRequest{Bundle: &bundle})
+		require.NoError(t, err)
+		require.NotEqual(t, thread.Id, imported.Id)
+		require.Equal(t, []string{importerId}, imported.UserIds)
+
+		messages, _, err := as.GetMessages(imported.Id)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(messages))
+		require.Equal(t, importerId, messages[0].UserId)
+		require.Equal(t, imported.Id, messages[0].ParentId)
+		require.Equal(t, messages[0].Id, messages[1].ParentId)
+		require.Equal(t, 1, len(messages[1].AssetIds))
+		require.NotEqual(t, asset.Id, messages[1].AssetIds[0])
+
+		_, content, err := as.OpenAsset(messages[1].AssetIds[0])
+		require.NoError(t, err)
+		content.Close()
+	})
+
+	t.Run("openai round trip", func(t *testing.T) {
+		conversation := chattypes.OpenAIConversation{}
+		require.NoError(t, json.Unmarshal(export(t, chattypes.ExportFormatOpenAI), &conversation))
+		require.Equal(t, 2, len(conversation.Messages))
+		require.Equal(t, "user", conversation.Messages[0].Role)
+		require.Equal(t, "assistant", conversation.Messages[1].Role)
+
+		imported, err := as.ImportThread(userId, &chattypes.ImportThreadRequest{Conversation: &conversation})
+		require.NoError(t, err)
+		require.Equal(t, "Draw a cat", imported.Title)
+
+		messages, _, err := as.GetMessages(imported.Id)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(messages))
+		require.Equal(t, "Sure, here is your image", messages[1].Content)
+	})
+
+	t.Run("invalid imports", func(t *testing.T) {
+		_, err := as.ImportThread(userId, &chattypes.ImportThreadRequest{})
+		require.True(t, errors.Is(err, chatservice.ErrInvalidImport))
+
+		_, err = as.ImportThread(userId, &chattypes.ImportThreadRequest{
+			Conversation: &chattypes.OpenAIConversation{Messages: []*chattypes.OpenAIMessage{
+				{Role: "robot", Content: "beep"},
+			}},
+		})
+		require.True(t, errors.Is(err, chatservice.ErrInvalidImport))
+
+		_, err = as.ImportThread(userId, &chattypes.ImportThreadRequest{
+			Bundle: &chattypes.ThreadBundle{
+				Thread: &chattypes.Thread{Id: "t1", Title: "Broken"},
+				Assets: []*chattypes.Asset{{Id: "a1", Content: "not base64!"}},
+			},
+		})
+		require.True(t, errors.Is(err, chatservice.ErrInvalidImport))
+	})
+}
@@ -0,0 +1,219 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+var (
+	ErrThreadNotFound = errors.New("thread not found")
+	ErrInvalidExport  = errors.New("invalid export")
+)
+
+// ExportThread writes a thread of a user to w in the given format.
+func (a *ChatService) ExportThread(userId string, threadId string, format chattypes.ExportFormat, w io.Writer) error {
+	thread, err := a.ownThread(userId, threadId)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case chattypes.ExportFormatJSON:
+		return a.exportBundle(thread, w)
+	case chattypes.ExportFormatMarkdown:
+		return a.exportMarkdown(thread, w)
+	case chattypes.ExportFormatOpenAI:
+		return a.exportOpenAI(thread, w)
+	}
+
+	return fmt.Errorf("%w: unknown format '%v'", ErrInvalidExport, format)
+}
+
+func (a *ChatService) ownThread(userId string, threadId string) (*chattypes.Thread, error) {
+	thread, found, err := a.GetThread(threadId)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: '%v'", ErrThreadNotFound, threadId)
+	}
+	for _, id := range thread.UserIds {
+		if id == userId {
+			return thread, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: '%v'", ErrThreadNotFound, threadId)
+}
+
+func (a *ChatService) exportBundle(thread *chattypes.Thread, w io.Writer) error {
+	messages, err := a.messagesStore.Query(
+		datastore.Equal("threadId", thread.Id),
+	).Find()
+	if err != nil {
+		return err
+	}
+	sort.Sort(chattypes.ByTime(messages))
+
+	assetIds := []string{}
+	for _, message := range messages {
+		assetIds = append(assetIds, message.AssetIds...)
+	}
+	assets, err := a.inlineAssets(assetIds)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(chattypes.ThreadBundle{
+		Version:  chattypes.ThreadBundleVersion,
+		Thread:   thread,
+		Messages: messages,
+		Assets:   assets,
+	})
+}
+
+// inlineAssets returns copies of assets with their content inlined as base64
+func (a *ChatService) inlineAssets(assetIds []string) ([]*chattypes.Asset, error) {
+	ret := []*chattypes.Asset{}
+	for _, assetId := range assetIds {
+		asset, content, err := a.OpenAsset(assetId)
+		if errors.Is(err, ErrAssetNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(content)
+		content.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading asset '%v'", assetId)
+		}
+
+		inlined := *asset
+		inlined.Content = base64.StdEncoding.EncodeToString(data)
+		inlined.StorageKey = ""
+		inlined.Url = ""
+		ret = append(ret, &inlined)
+	}
+
+	return ret, nil
+}
+
+// transcript returns the messages of the active branch without summaries
+func (a *ChatService) transcript(threadId string) ([]*chattypes.Message, error) {
+	messages, _, err := a.GetMessages(threadId)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []*chattypes.Message{}
+	for _, message := range messages {
+		if len(message.SummaryOf) > 0 {
+			continue
+		}
+		ret = append(ret, message)
+	}
+	return ret, nil
+}
+
+func (a *ChatService) exportMarkdown(thread *chattypes.Thread, w io.Writer) error {
+	messages, err := a.transcript(thread.Id)
+	if err != nil {
+		return err
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "# %v\n", thread.Title)
+
+	for _, message := range messages {
+		role := string(message.GetRole())
+		fmt.Fprintf(sb, "\n### %v%v\n\n", strings.ToUpper(role[:1]), role[1:])
+
+		if message.Content != "" {
+			sb.WriteString(strings.TrimSpace(message.Content))
+			sb.WriteString("\n")
+		}
+
+		if message.ToolCall != nil {
+			arguments, _ := json.MarshalIndent(message.ToolCall.Arguments, "", "  ")
+			fmt.Fprintf(sb, "\nCalled `%v` with:\n\n```json\n%s\n```\n", message.ToolCall.Name, arguments)
+		}
+
+		assets, err := a.inlineAssets(message.AssetIds)
+		if err != nil {
+			return err
+		}
+		for _, asset := range assets {
+			contentType := asset.Type
+			if contentType == "" {
+				contentType = "image/png"
+			}
+			fmt.Fprintf(sb, "\n![%v](data:%v;base64,%v)\n", asset.Id, contentType, asset.Content)
+		}
+
+		if len(message.Citations) > 0 {
+			sb.WriteString("\nSources:\n\n")
+			for _, citation := range message.Citations {
+				fmt.Fprintf(sb, "%v. %v\n", citation.Index, citation.DocumentName)
+			}
+		}
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+func (a *ChatService) exportOpenAI(thread *chattypes.Thread, w io.Writer) error {
+	messages, err := a.transcript(thread.Id)
+	if err != nil {
+		return err
+	}
+
+	conversation := chattypes.OpenAIConversation{
+		Messages: []*chattypes.OpenAIMessage{},
+	}
+	for _, message := range messages {
+		openaiMessage := &chattypes.OpenAIMessage{
+			Role:       string(message.GetRole()),
+			Content:    message.Content,
+			ToolCallId: message.ToolCallId,
+		}
+		if message.ToolCall != nil {
+			arguments, err := json.Marshal(message.ToolCall.Arguments)
+			if err != nil {
+				return err
+			}
+			openaiMessage.ToolCalls = []*chattypes.OpenAIToolCall{{
+				Id:   message.ToolCall.Id,
+				Type: "function",
+				Function: chattypes.OpenAIFunctionCall{
+					Name:      message.ToolCall.Name,
+					Arguments: string(arguments),
+				},
+			}}
+		}
+		conversation.Messages = append(conversation.Messages, openaiMessage)
+	}
+
+	// the encoder ends the line
+	return json.NewEncoder(w).Encode(conversation)
+}
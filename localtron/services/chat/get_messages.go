@@ -15,13 +15,19 @@ import (
 	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
 )
 
-func (a *ChatService) GetMessages(threadId string) ([]*chattypes.Message, error) {
+/*
+GetMessages returns the messages of the active branch of a thread
+and the ids of the alternatives of the messages on it that have any.
+*/
+func (a *ChatService) GetMessages(threadId string) ([]*chattypes.Message, map[string][]string, error) {
 	messages, err := a.messagesStore.Query(
 		datastore.Equal("threadId", threadId),
 	).OrderBy("createdAt", false).Find()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return messages, nil
+	branch, alternatives := newMessageTree(messages).branch()
+
+	return branch, alternatives, nil
 }
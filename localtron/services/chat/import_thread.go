@@ -0,0 +1,261 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/logger"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+var ErrInvalidImport = errors.New("invalid import")
+
+const (
+	importTitleLength  = 50
+	defaultImportTitle = "Imported chat"
+)
+
+/*
+ImportThread recreates an exported thread under a user.
+Threads, messages and assets get new ids so the same export
+can be imported more than once.
+*/
+func (a *ChatService) ImportThread(userId string, req *chattypes.ImportThreadRequest) (*chattypes.Thread, error) {
+	switch {
+	case req.Bundle != nil && req.Conversation != nil:
+		return nil, fmt.Errorf("%w: both bundle and conversation are set", ErrInvalidImport)
+	case req.Bundle != nil:
+		return a.importBundle(userId, req.Bundle, req.Title)
+	case req.Conversation != nil:
+		return a.importConversation(userId, req.Conversation, req.Title)
+	}
+
+	return nil, fmt.Errorf("%w: no bundle or conversation", ErrInvalidImport)
+}
+
+func (a *ChatService) importBundle(userId string, bundle *chattypes.ThreadBundle, title string) (*chattypes.Thread, error) {
+	if bundle.Thread == nil {
+		return nil, fmt.Errorf("%w: bundle has no thread", ErrInvalidImport)
+	}
+	if bundle.Version > chattypes.ThreadBundleVersion {
+		return nil, fmt.Errorf("%w: unsupported bundle version %v", ErrInvalidImport, bundle.Version)
+	}
+
+	newIds := map[string]string{}
+	newId := func(oldId string) string {
+		id, ok := newIds[oldId]
+		if !ok {
+			id = uuid.New().String()
+			newIds[oldId] = id
+		}
+		return id
+	}
+
+	thread := *bundle.Thread
+	thread.Id = newId(bundle.Thread.Id)
+	thread.UserIds = []string{userId}
+	// collections are not exported and belong to the exporting user
+	thread.CollectionIds = nil
+	thread.UpdatedAt = time.Now()
+	if title != "" {
+		thread.Title = title
+	}
+
+	assets := []*chattypes.Asset{}
+	for _, v := range bundle.Assets {
+		if v.Content == "" {
+			continue
+		}
+		_, err := base64.StdEncoding.DecodeString(v.Content)
+		if err != nil {
+			return nil, fmt.Errorf("%w: content of asset '%v' is not base64", ErrInvalidImport, v.Id)
+		}
+		asset := *v
+		asset.Id = newId(v.Id)
+		asset.Url = assetUrl(asset.Id)
+		asset.StorageKey = ""
+		assets = append(assets, &asset)
+	}
+
+	messageIds := map[string]bool{}
+	for _, message := range bundle.Messages {
+		messageIds[message.Id] = true
+	}
+
+	messages := []*chattypes.Message{}
+	for _, v := range bundle.Messages {
+		message := *v
+		message.Id = newId(v.Id)
+		message.ThreadId = thread.Id
+		if v.ParentId != "" {
+			if v.ParentId != bundle.Thread.Id && !messageIds[v.ParentId] {
+				return nil, fmt.Errorf("%w: parent of message '%v' is missing", ErrInvalidImport, v.Id)
+			}
+			message.ParentId = newId(v.ParentId)
+		}
+		if v.UserId != "" {
+			message.UserId = userId
+		}
+
+		message.AssetIds = nil
+		for _, assetId := range v.AssetIds {
+			if id, ok := newIds[assetId]; ok {
+				message.AssetIds = append(message.AssetIds, id)
+			}
+		}
+		message.SummaryOf = nil
+		for _, summarizedId := range v.SummaryOf {
+			message.SummaryOf = append(message.SummaryOf, newId(summarizedId))
+		}
+
+		messages = append(messages, &message)
+	}
+
+	return a.saveImport(&thread, messages, assets)
+}
+
+func (a *ChatService) importConversation(userId string, conversation *chattypes.OpenAIConversation, title string) (*chattypes.Thread, error) {
+	if len(conversation.Messages) == 0 {
+		return nil, fmt.Errorf("%w: conversation has no messages", ErrInvalidImport)
+	}
+
+	now := time.Now()
+	thread := &chattypes.Thread{
+		Id:        uuid.New().String(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserIds:   []string{userId},
+		Title:     title,
+	}
+
+	messages := []*chattypes.Message{}
+	parentId := thread.Id
+	for i, v := range conversation.Messages {
+		role := chattypes.MessageRole(v.Role)
+		switch role {
+		case chattypes.MessageRoleUser,
+			chattypes.MessageRoleAssistant,
+			chattypes.MessageRoleSystem,
+			chattypes.MessageRoleTool:
+		default:
+			return nil, fmt.Errorf("%w: unknown role '%v' of message %v", ErrInvalidImport, v.Role, i)
+		}
+
+		message := &chattypes.Message{
+			Id:         uuid.New().String(),
+			ThreadId:   thread.Id,
+			ParentId:   parentId,
+			Role:       role,
+			Content:    v.Content,
+			ToolCallId: v.ToolCallId,
+			// keeps the order of messages
+			CreatedAt: now.Add(time.Duration(i) * time.Millisecond),
+		}
+		if role == chattypes.MessageRoleUser {
+			message.UserId = userId
+			if thread.Title == "" {
+				thread.Title = titleOf(v.Content)
+			}
+		}
+		if len(v.ToolCalls) > 0 {
+			call := v.ToolCalls[0]
+			arguments := map[string]any{}
+			if call.Function.Arguments != "" {
+				err := json.Unmarshal([]byte(call.Function.Arguments), &arguments)
+				if err != nil {
+					return nil, fmt.Errorf("%w: arguments of tool call '%v' are not a JSON object", ErrInvalidImport, call.Id)
+				}
+			}
+			message.ToolCall = &chattypes.ToolCall{
+				Id:        call.Id,
+				Name:      call.Function.Name,
+				Arguments: arguments,
+			}
+		}
+
+		messages = append(messages, message)
+		parentId = message.Id
+	}
+
+	if thread.Title == "" {
+		thread.Title = defaultImportTitle
+	}
+
+	return a.saveImport(thread, messages, nil)
+}
+
+// saveImport saves the thread first so nothing is left behind when that fails
+func (a *ChatService) saveImport(
+	thread *chattypes.Thread,
+	messages []*chattypes.Message,
+	assets []*chattypes.Asset,
+) (*chattypes.Thread, error) {
+	thread, err := a.AddThread(thread)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(assets) > 0 {
+		err = a.UpsertAssets(assets)
+		if err != nil {
+			a.deleteImport(thread.Id)
+			return nil, errors.Wrap(err, "error saving assets")
+		}
+	}
+
+	err = a.messagesStore.CreateMany(messages)
+	if err != nil {
+		a.deleteImport(thread.Id)
+		return nil, errors.Wrap(err, "error saving messages")
+	}
+
+	a.firehoseService.Publish(chattypes.EventMessageAdded{
+		ThreadId: thread.Id,
+	})
+
+	return thread, nil
+}
+
+// deleteImport deletes the thread of an import which could not be saved
+func (a *ChatService) deleteImport(threadId string) {
+	err := a.DeleteThread(threadId)
+	if err != nil {
+		logger.Error("Error deleting thread of failed import",
+			slog.String("threadId", threadId),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// titleOf returns the start of the first line of a text
+func titleOf(text string) string {
+	runes := []rune(strings.TrimSpace(text))
+	for i, r := range runes {
+		if r == '\n' {
+			runes = runes[:i]
+			break
+		}
+	}
+	if len(runes) > importTitleLength {
+		return string(runes[:importTitleLength]) + "…"
+	}
+	return string(runes)
+}
@@ -0,0 +1,56 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice
+
+import (
+	"log/slog"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	"github.com/singulatron/singulatron/localtron/logger"
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+/*
+migrateInlineAssets moves the base64 content of assets saved by
+earlier versions to the asset file store.
+*/
+func (a *ChatService) migrateInlineAssets() error {
+	assets, err := a.assetsStore.Query(
+		datastore.All(),
+	).Find()
+	if err != nil {
+		return err
+	}
+
+	inline := []*chattypes.Asset{}
+	for _, asset := range assets {
+		if asset.Content == "" {
+			continue
+		}
+		err = a.storeInlineContent(asset)
+		if err != nil {
+			// leave the asset inline, it can still be served
+			logger.Warn("Cannot migrate inline asset",
+				slog.String("assetId", asset.Id),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		inline = append(inline, asset)
+	}
+	if len(inline) == 0 {
+		return nil
+	}
+
+	logger.Info("Migrated inline assets to the file store", slog.Int("count", len(inline)))
+
+	return a.assetsStore.UpsertMany(inline)
+}
@@ -0,0 +1,63 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	"github.com/singulatron/singulatron/localtron/filestore"
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+var ErrAssetNotFound = errors.New("asset not found")
+
+/*
+OpenAsset returns an asset and its content. The caller must close the content.
+*/
+func (a *ChatService) OpenAsset(id string) (*chattypes.Asset, io.ReadCloser, error) {
+	asset, found, err := a.assetsStore.Query(
+		datastore.Id(id),
+	).FindOne()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !found {
+		return nil, nil, fmt.Errorf("%w: '%v'", ErrAssetNotFound, id)
+	}
+
+	if asset.StorageKey == "" {
+		// inline assets not migrated yet
+		content, err := base64.StdEncoding.DecodeString(asset.Content)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "error decoding inline asset")
+		}
+		if len(content) == 0 {
+			return nil, nil, fmt.Errorf("%w: '%v' has no content", ErrAssetNotFound, id)
+		}
+		return asset, io.NopCloser(bytes.NewReader(content)), nil
+	}
+
+	content, err := a.assetFiles.Open(asset.StorageKey)
+	if errors.Is(err, filestore.ErrNotFound) {
+		return nil, nil, fmt.Errorf("%w: '%v' has no content", ErrAssetNotFound, id)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return asset, content, nil
+}
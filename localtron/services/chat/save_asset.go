@@ -0,0 +1,55 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice
+
+import (
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+/*
+SaveAsset stores the content read from r in the asset file store
+and saves an asset referencing it.
+*/
+func (a *ChatService) SaveAsset(r io.Reader, contentType string) (*chattypes.Asset, error) {
+	key, size, err := a.assetFiles.Put(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "error storing asset content")
+	}
+
+	id := uuid.New().String()
+	now := time.Now()
+	asset := &chattypes.Asset{
+		Id:         id,
+		Url:        assetUrl(id),
+		StorageKey: key,
+		Size:       size,
+		Type:       contentType,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	err = a.assetsStore.Create(asset)
+	if err != nil {
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+func assetUrl(id string) string {
+	return "/chat/asset/" + id
+}
@@ -0,0 +1,106 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+var ErrInvalidSearch = errors.New("invalid search")
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+/*
+Search finds the threads of a user whose title matches the query
+and the messages of those threads matching it, newest first.
+Title matches come before message matches.
+*/
+func (a *ChatService) Search(userId string, req *chattypes.SearchRequest) ([]*chattypes.SearchResult, error) {
+	terms := datastore.Words(req.Query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w: query has no words", ErrInvalidSearch)
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	threads, err := a.GetThreads(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	threadsById := map[string]*chattypes.Thread{}
+	threadIds := []string{}
+	for _, thread := range threads {
+		if req.ThreadId != "" && thread.Id != req.ThreadId {
+			continue
+		}
+		threadsById[thread.Id] = thread
+		threadIds = append(threadIds, thread.Id)
+	}
+
+	results := []*chattypes.SearchResult{}
+	if len(threadIds) == 0 {
+		return results, nil
+	}
+
+	titleMatches, err := a.threadsStore.Query(
+		datastore.Equal("id", threadIds),
+		datastore.Match("title", req.Query),
+	).OrderBy("createdAt", true).Limit(limit).Find()
+	if err != nil {
+		return nil, errors.Wrap(err, "error searching threads")
+	}
+	for _, thread := range titleMatches {
+		snippet, highlights := snippetOf(thread.Title, terms)
+		results = append(results, &chattypes.SearchResult{
+			Thread:     threadsById[thread.Id],
+			Snippet:    snippet,
+			Highlights: highlights,
+		})
+	}
+
+	if len(results) >= limit {
+		return results, nil
+	}
+
+	messages, err := a.messagesStore.Query(
+		datastore.Equal("threadId", threadIds),
+		datastore.Match("content", req.Query),
+	).OrderBy("createdAt", true).Limit(limit - len(results)).Find()
+	if err != nil {
+		return nil, errors.Wrap(err, "error searching messages")
+	}
+	for _, message := range messages {
+		snippet, highlights := snippetOf(message.Content, terms)
+		results = append(results, &chattypes.SearchResult{
+			Thread:     threadsById[message.ThreadId],
+			Message:    message,
+			Snippet:    snippet,
+			Highlights: highlights,
+		})
+	}
+
+	return results, nil
+}
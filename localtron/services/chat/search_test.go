@@ -0,0 +1,99 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	configservice "github.com/singulatron/singulatron/localtron/services/config"
+	firehoseservice "github.com/singulatron/singulatron/localtron/services/firehose"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func TestSearch(t *testing.T) {
+	cs, err := configservice.NewConfigService()
+	require.NoError(t, err)
+	us, err := userservice.NewUserService(cs)
+	require.NoError(t, err)
+	fs, err := firehoseservice.NewFirehoseService(us)
+	require.NoError(t, err)
+	as, err := chatservice.NewChatService(cs, fs, us, nil)
+	require.NoError(t, err)
+
+	// unique words so earlier runs do not interfere
+	word := "zq" + uuid.New().String()[0:8]
+	userId := uuid.New().String()
+	otherUserId := uuid.New().String()
+
+	addThread := func(userId string, title string, contents ...string) string {
+		thread, err := as.AddThread(&chattypes.Thread{
+			Id:      uuid.New().String(),
+			Title:   title,
+			UserIds: []string{userId},
+		})
+		require.NoError(t, err)
+		for _, content := range contents {
+			err = as.AddMessage(&chattypes.Message{
+				Id:       uuid.New().String(),
+				ThreadId: thread.Id,
+				Content:  content,
+			})
+			require.NoError(t, err)
+		}
+		return thread.Id
+	}
+
+	threadId := addThread(userId, "About "+word, "nothing here", "the "+word+" answer")
+	addThread(userId, "Unrelated", "another "+word+"s message")
+	addThread(otherUserId, "Private "+word, "private "+word)
+
+	t.Run("title and messages of own threads", func(t *testing.T) {
+		results, err := as.Search(userId, &chattypes.SearchRequest{Query: word})
+		require.NoError(t, err)
+		require.Equal(t, 3, len(results))
+
+		require.Nil(t, results[0].Message)
+		require.Equal(t, threadId, results[0].Thread.Id)
+
+		for _, result := range results[1:] {
+			require.NotNil(t, result.Message)
+			require.Equal(t, 1, len(result.Highlights))
+		}
+	})
+
+	t.Run("restricted to a thread", func(t *testing.T) {
+		results, err := as.Search(userId, &chattypes.SearchRequest{
+			Query:    "answer " + word,
+			ThreadId: threadId,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(results))
+		require.Equal(t, "the "+word+" answer", results[0].Message.Content)
+		require.Equal(t, 2, len(results[0].Highlights))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		results, err := as.Search(userId, &chattypes.SearchRequest{Query: word, Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, 2, len(results))
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		_, err := as.Search(userId, &chattypes.SearchRequest{Query: " ? "})
+		require.True(t, errors.Is(err, chatservice.ErrInvalidSearch))
+	})
+}
@@ -0,0 +1,56 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice
+
+import (
+	"errors"
+	"time"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+var ErrMessageNotFound = errors.New("message not found")
+
+// SelectMessage makes the branch of a message the active branch of its thread
+func (a *ChatService) SelectMessage(messageId string) error {
+	message, found, err := a.messagesStore.Query(
+		datastore.Id(messageId),
+	).FindOne()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrMessageNotFound
+	}
+
+	messages, err := a.messagesStore.Query(
+		datastore.Equal("threadId", message.ThreadId),
+	).Find()
+	if err != nil {
+		return err
+	}
+
+	err = a.messagesStore.Query(
+		datastore.Equal("id", newMessageTree(messages).path(messageId)),
+	).UpdateFields(map[string]any{
+		"selectedAt": time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	a.firehoseService.Publish(chattypes.EventThreadUpdate{
+		ThreadId: message.ThreadId,
+	})
+
+	return nil
+}
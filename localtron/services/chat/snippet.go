@@ -0,0 +1,93 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice
+
+import (
+	"strings"
+	"unicode"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+const (
+	snippetLength = 160
+	// snippetLead is how much text is kept before the first match
+	snippetLead = 60
+)
+
+/*
+snippetOf cuts the part of a text around the first word starting with
+one of the terms and highlights the matching words in it.
+Terms are expected to be lowercase, see datastore.Words.
+*/
+func snippetOf(text string, terms []string) (string, []*chattypes.Highlight) {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+
+	matches := []*chattypes.Highlight{}
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		word := strings.ToLower(string(runes[start:end]))
+		for _, term := range terms {
+			if strings.HasPrefix(word, term) {
+				matches = append(matches, &chattypes.Highlight{Start: start, End: end})
+				break
+			}
+		}
+		start = end
+	}
+
+	from := 0
+	if len(matches) > 0 && matches[0].Start > snippetLead {
+		from = matches[0].Start - snippetLead
+		// do not start in the middle of a word
+		for from < matches[0].Start && isWordRune(runes[from-1]) {
+			from++
+		}
+	}
+	to := from + snippetLength
+	if to > len(runes) {
+		to = len(runes)
+	}
+
+	snippet := string(runes[from:to])
+	offset := -from
+	if from > 0 {
+		snippet = "…" + snippet
+		offset++
+	}
+	if to < len(runes) {
+		snippet += "…"
+	}
+
+	highlights := []*chattypes.Highlight{}
+	for _, match := range matches {
+		if match.Start < from || match.End > to {
+			continue
+		}
+		highlights = append(highlights, &chattypes.Highlight{
+			Start: match.Start + offset,
+			End:   match.End + offset,
+		})
+	}
+
+	return snippet, highlights
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,48 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnippetOf(t *testing.T) {
+	highlighted := func(snippet string, start, end int) string {
+		return string([]rune(snippet)[start:end])
+	}
+
+	t.Run("short text", func(t *testing.T) {
+		snippet, highlights := snippetOf("Hello,\n\nwonderful World", []string{"wor", "hello"})
+		require.Equal(t, "Hello, wonderful World", snippet)
+		require.Equal(t, 2, len(highlights))
+		require.Equal(t, "Hello", highlighted(snippet, highlights[0].Start, highlights[0].End))
+		require.Equal(t, "World", highlighted(snippet, highlights[1].Start, highlights[1].End))
+	})
+
+	t.Run("long text is cut around the first match", func(t *testing.T) {
+		text := strings.Repeat("lorem ipsum ", 30) + "the ünïcode needle " + strings.Repeat("dolor sit ", 30)
+		snippet, highlights := snippetOf(text, []string{"needle"})
+		require.True(t, strings.HasPrefix(snippet, "…"))
+		require.True(t, strings.HasSuffix(snippet, "…"))
+		require.Equal(t, 1, len(highlights))
+		require.Equal(t, "needle", highlighted(snippet, highlights[0].Start, highlights[0].End))
+		require.False(t, strings.HasPrefix(snippet, "…psum"))
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		snippet, highlights := snippetOf("nothing to see", []string{"xyz"})
+		require.Equal(t, "nothing to see", snippet)
+		require.Equal(t, 0, len(highlights))
+	})
+}
@@ -20,7 +20,7 @@ func TestMessageCreatesThread(t *testing.T) {
 	require.NoError(t, err)
 	fs, err := firehoseservice.NewFirehoseService(us)
 	require.NoError(t, err)
-	as, err := chatservice.NewChatService(cs, fs, us)
+	as, err := chatservice.NewChatService(cs, fs, us, nil)
 	require.NoError(t, err)
 
 	t.Run("no thread id", func(t *testing.T) {
@@ -67,6 +67,16 @@ func TestMessageCreatesThread(t *testing.T) {
 		threadId = thread.Id
 	})
 
+	t.Run("collections can't be checked", func(t *testing.T) {
+		err := as.CheckThreadCollections(userId, &chattypes.Thread{
+			Id:            uuid.New().String(),
+			UserIds:       []string{userId},
+			CollectionIds: []string{"col-1"},
+		})
+		require.Error(t, err)
+		require.NotErrorIs(t, err, chatservice.ErrUnauthorized)
+	})
+
 	t.Run("no user id", func(t *testing.T) {
 		err := as.AddMessage(&chattypes.Message{
 			Id:       uuid.New().String(),
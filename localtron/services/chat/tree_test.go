@@ -0,0 +1,43 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chatservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+func TestLegacyMessagesFollowEachOther(t *testing.T) {
+	start := time.Now()
+	messages := []*chattypes.Message{
+		{Id: "1", ThreadId: "t", CreatedAt: start},
+		{Id: "2", ThreadId: "t", CreatedAt: start.Add(time.Second)},
+		{Id: "summary", ThreadId: "t", CreatedAt: start.Add(time.Second + time.Millisecond), SummaryOf: []string{"1", "2"}},
+		{Id: "3", ThreadId: "t", CreatedAt: start.Add(2 * time.Second)},
+		{Id: "4", ThreadId: "t", ParentId: "3", CreatedAt: start.Add(3 * time.Second)},
+	}
+
+	tree := newMessageTree(messages)
+	branch, alternatives := tree.branch()
+
+	ids := []string{}
+	for _, message := range branch {
+		ids = append(ids, message.Id)
+	}
+	assert.Equal(t, []string{"1", "2", "summary", "3", "4"}, ids)
+	assert.Empty(t, alternatives)
+	assert.Equal(t, "4", tree.leaf())
+	assert.Equal(t, []string{"4", "3", "2", "1"}, tree.path("4"))
+}
@@ -0,0 +1,104 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chattypes
+
+type ExportFormat string
+
+const (
+	// ExportFormatMarkdown is a readable transcript of the active branch
+	// with images inlined as data URLs, for sharing in documents.
+	ExportFormatMarkdown ExportFormat = "markdown"
+	// ExportFormatJSON is a lossless ThreadBundle of every branch of a thread,
+	// for backups and moving threads between machines.
+	ExportFormatJSON ExportFormat = "json"
+	// ExportFormatOpenAI is the active branch as a JSONL line of
+	// OpenAI style messages, the format of fine-tuning datasets.
+	ExportFormatOpenAI ExportFormat = "openai"
+)
+
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case ExportFormatOpenAI:
+		return "application/jsonl"
+	}
+	return "application/json"
+}
+
+func (f ExportFormat) Extension() string {
+	switch f {
+	case ExportFormatMarkdown:
+		return ".md"
+	case ExportFormatOpenAI:
+		return ".jsonl"
+	}
+	return ".json"
+}
+
+// ThreadBundleVersion is the version of the ThreadBundle format
+const ThreadBundleVersion = 1
+
+/*
+ThreadBundle is a thread with all its messages and assets.
+The content of assets is inlined as base64.
+*/
+type ThreadBundle struct {
+	Version  int        `json:"version"`
+	Thread   *Thread    `json:"thread"`
+	Messages []*Message `json:"messages"`
+	Assets   []*Asset   `json:"assets,omitempty"`
+}
+
+// OpenAIConversation is a line of an OpenAI fine-tuning dataset
+type OpenAIConversation struct {
+	Messages []*OpenAIMessage `json:"messages"`
+}
+
+type OpenAIMessage struct {
+	Role       string            `json:"role"`
+	Content    string            `json:"content"`
+	ToolCalls  []*OpenAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallId string            `json:"tool_call_id,omitempty"`
+}
+
+type OpenAIToolCall struct {
+	Id       string             `json:"id"`
+	Type     string             `json:"type"`
+	Function OpenAIFunctionCall `json:"function"`
+}
+
+type OpenAIFunctionCall struct {
+	Name string `json:"name"`
+	// Arguments are JSON encoded
+	Arguments string `json:"arguments"`
+}
+
+type ExportThreadRequest struct {
+	ThreadId string       `json:"threadId"`
+	Format   ExportFormat `json:"format"`
+}
+
+/*
+ImportThreadRequest recreates a thread under the caller.
+Exactly one of Bundle and Conversation must be set.
+*/
+type ImportThreadRequest struct {
+	Bundle       *ThreadBundle       `json:"bundle,omitempty"`
+	Conversation *OpenAIConversation `json:"conversation,omitempty"`
+	// Title of the imported thread, defaults to the title in the bundle
+	// or the start of the first user message of a conversation
+	Title string `json:"title,omitempty"`
+}
+
+type ImportThreadResponse struct {
+	Thread *Thread `json:"thread"`
+}
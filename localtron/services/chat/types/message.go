@@ -12,25 +12,90 @@ package chattypes
 
 import (
 	"time"
+
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+type MessageRole string
+
+const (
+	MessageRoleUser      MessageRole = "user"
+	MessageRoleAssistant MessageRole = "assistant"
+	MessageRoleSystem    MessageRole = "system"
+	// MessageRoleTool messages hold the result of a tool call
+	MessageRoleTool MessageRole = "tool"
 )
 
 type Message struct {
 	Id       string `json:"id"`
 	ThreadId string `json:"threadId"`
 	Content  string `json:"content"`
+	// ParentId is the message this one follows, the thread id for the first
+	// messages of a thread. Messages with the same parent are alternative branches.
+	// Messages saved before branching was introduced don't have it
+	// and follow the message created before them.
+	ParentId string `json:"parentId,omitempty"`
+	// SelectedAt is when the branch of the message was last selected.
+	// Of alternatives the last created or selected one is on the active branch.
+	SelectedAt time.Time `json:"selectedAt,omitempty"`
+	// Role of the author of the message.
+	// Older messages might not have it, see GetRole.
+	Role MessageRole `json:"role,omitempty"`
 	// UserId is saved when the user is logged in to an account
 	// @todo not used yet
 	UserId    string    `json:"userId,omitempty"`
 	CreatedAt time.Time `json:"createdAt,omitempty"`
 	UpdatedAt time.Time `json:"updatedAt,omitempty"`
 	AssetIds  []string  `json:"assetIds,omitempty"`
+	// SummaryOf is set on synthetic messages which stand in for older
+	// messages of the thread that no longer fit the context window of a model.
+	// It lists the ids of the summarized messages.
+	SummaryOf []string `json:"summaryOf,omitempty"`
+	// Truncated is true when the answer was cut short
+	// by canceling the prompt it answers
+	Truncated bool `json:"truncated,omitempty"`
+	// Structured is the parsed JSON of answers to prompts with a response schema
+	Structured any `json:"structured,omitempty"`
+	// ToolCall is set on answers calling a tool instead of answering
+	ToolCall *ToolCall `json:"toolCall,omitempty"`
+	// ToolCallId is the id of the tool call the result of a tool message is for
+	ToolCallId string `json:"toolCallId,omitempty"`
+	// Citations are the document passages the answer was given from
+	Citations []*Citation `json:"citations,omitempty"`
+	// ImageParameters are the parameters the images of the message
+	// were generated with, including the seed
+	ImageParameters *modeltypes.ImageParameters `json:"imageParameters,omitempty"`
+}
+
+// Citation is a passage of a document shown to the model as [Index]
+type Citation struct {
+	Index        int     `json:"index"`
+	CollectionId string  `json:"collectionId"`
+	DocumentId   string  `json:"documentId"`
+	DocumentName string  `json:"documentName"`
+	Chunk        int     `json:"chunk"`
+	Text         string  `json:"text"`
+	Score        float64 `json:"score"`
+}
+
+// ToolCall is a model asking to run a tool
+type ToolCall struct {
+	Id string `json:"id"`
+	// Name is the id of the tool
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments,omitempty"`
 }
 
 type Asset struct {
-	Id  string `json:"id"`
+	Id string `json:"id"`
+	/* Url the content of the asset can be downloaded from, eg. /chat/asset/{id} */
 	Url string `json:"url,omitempty"`
-	/* Some assets might have the content directly in them as base64
-	encoded strings */
+	/* StorageKey is the key of the content in the file store */
+	StorageKey string `json:"storageKey,omitempty"`
+	/* Size of the content in bytes */
+	Size int64 `json:"size,omitempty"`
+	/* Legacy assets had the content directly in them as base64
+	encoded strings. These are moved to the file store on startup. */
 	Content    string    `json:"content,omitempty"`
 	Type       string    `json:"type,omitempty"`
 	Decription string    `json:"description,omitempty"`
@@ -50,6 +115,20 @@ func (c *Message) GetUpdatedAt() string {
 	return c.Id
 }
 
+// GetRole returns the role of the message author.
+// Messages saved before roles were introduced only have a user id
+// when they were sent by a user, so the role is derived from that.
+func (c *Message) GetRole() MessageRole {
+	if c.Role != "" {
+		return c.Role
+	}
+	if c.UserId != "" {
+		return MessageRoleUser
+	}
+
+	return MessageRoleAssistant
+}
+
 type ByTime []*Message
 
 func (a ByTime) Len() int      { return len(a) }
@@ -71,10 +150,20 @@ type GetMessagesRequest struct {
 }
 
 type GetMessagesResponse struct {
+	// Messages are the messages of the active branch of the thread
 	Messages []*Message `json:"messages"`
 	Assets   []*Asset   `json:"assets,omitempty"`
+	// Alternatives are the ids of the alternatives (including itself)
+	// of the messages of the active branch that have any
+	Alternatives map[string][]string `json:"alternatives,omitempty"`
 }
 
+type SelectMessageRequest struct {
+	MessageId string `json:"messageId"`
+}
+
+type SelectMessageResponse struct{}
+
 type DeleteMessageRequest struct {
 	MessageId string `json:"messageId"`
 }
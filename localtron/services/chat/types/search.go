@@ -0,0 +1,44 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package chattypes
+
+type SearchRequest struct {
+	// Query is the words to look for. Every word must be
+	// the start of a word in a message or a thread title.
+	Query string `json:"query"`
+	// ThreadId optionally restricts the search to a thread
+	ThreadId string `json:"threadId,omitempty"`
+	// Limit is the maximum number of results, 20 by default
+	Limit int `json:"limit,omitempty"`
+}
+
+type SearchResult struct {
+	Thread *Thread `json:"thread"`
+	// Message is the matching message, empty when the thread title matched
+	Message *Message `json:"message,omitempty"`
+	// Snippet is the part of the matching text around the first match
+	Snippet string `json:"snippet"`
+	// Highlights are the matched words in the snippet
+	Highlights []*Highlight `json:"highlights,omitempty"`
+}
+
+/*
+Highlight is a matched word of a snippet.
+Offsets are in characters (Unicode code points), not bytes.
+*/
+type Highlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type SearchResponse struct {
+	Results []*SearchResult `json:"results"`
+}
@@ -23,6 +23,15 @@ type Thread struct {
 	UserIds  []string `json:"userIds,omitempty"`
 
 	Title string `json:"title"`
+
+	// TemplateId is the prompt template used by the thread
+	TemplateId string `json:"templateId,omitempty"`
+	// TemplateVariables are the values of the variables of the template
+	TemplateVariables map[string]string `json:"templateVariables,omitempty"`
+
+	// CollectionIds are the document collections prompts
+	// of the thread are answered from
+	CollectionIds []string `json:"collectionIds,omitempty"`
 }
 
 func (c *Thread) GetId() string {
@@ -11,12 +11,23 @@
 package chatservice
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/singulatron/singulatron/localtron/datastore"
 	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	documentservice "github.com/singulatron/singulatron/localtron/services/document"
 )
 
-func (a *ChatService) UpdateThread(chatThread *chattypes.Thread) (*chattypes.Thread, error) {
-	err := a.threadsStore.Query(
+var ErrUnauthorized = errors.New("unauthorized")
+
+func (a *ChatService) UpdateThread(userId string, chatThread *chattypes.Thread) (*chattypes.Thread, error) {
+	err := a.CheckThreadCollections(userId, chatThread)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.threadsStore.Query(
 		datastore.Equal("id", chatThread.Id),
 	).Update(chatThread)
 
@@ -30,3 +41,21 @@ func (a *ChatService) UpdateThread(chatThread *chattypes.Thread) (*chattypes.Thr
 
 	return chatThread, nil
 }
+
+// CheckThreadCollections makes sure the user owns the document collections of a thread
+func (a *ChatService) CheckThreadCollections(userId string, chatThread *chattypes.Thread) error {
+	if len(chatThread.CollectionIds) == 0 {
+		return nil
+	}
+	if a.documentService == nil {
+		return errors.New("document collections can't be checked without the document service")
+	}
+
+	err := a.documentService.CheckCollections(userId, chatThread.CollectionIds)
+	if errors.Is(err, documentservice.ErrUnauthorized) ||
+		errors.Is(err, documentservice.ErrCollectionNotFound) {
+		return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	return err
+}
@@ -11,17 +11,55 @@
 package chatservice
 
 import (
+	"bytes"
+	"encoding/base64"
 	"time"
 
+	"github.com/pkg/errors"
+
 	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
 )
 
+/*
+UpsertAssets saves assets. Content inlined as base64 is moved
+to the asset file store so the datastore only keeps references.
+*/
 func (a *ChatService) UpsertAssets(assets []*chattypes.Asset) error {
 	now := time.Now()
 	for _, v := range assets {
 		if v.CreatedAt.IsZero() {
 			v.CreatedAt = now
 		}
+		err := a.storeInlineContent(v)
+		if err != nil {
+			return err
+		}
 	}
 	return a.assetsStore.UpsertMany(assets)
 }
+
+func (a *ChatService) storeInlineContent(asset *chattypes.Asset) error {
+	if asset.Content == "" {
+		return nil
+	}
+
+	content, err := base64.StdEncoding.DecodeString(asset.Content)
+	if err != nil {
+		return errors.Wrapf(err, "error decoding content of asset '%v'", asset.Id)
+	}
+
+	key, size, err := a.assetFiles.Put(bytes.NewReader(content))
+	if err != nil {
+		return errors.Wrapf(err, "error storing content of asset '%v'", asset.Id)
+	}
+
+	asset.StorageKey = key
+	asset.Size = size
+	asset.Content = ""
+	if asset.Url == "" {
+		asset.Url = assetUrl(asset.Id)
+	}
+	asset.UpdatedAt = time.Now()
+
+	return nil
+}
@@ -16,6 +16,29 @@ type DownloadServiceConfig struct {
 
 type ModelServiceConfig struct {
 	CurrentModelId string `json:"currentModelId" yaml:"currentModelId"`
+	// IdleTimeoutMinutes is how long a started model can go without
+	// being used by a prompt before its container is stopped.
+	// Zero keeps models running until they are stopped.
+	IdleTimeoutMinutes int `json:"idleTimeoutMinutes,omitempty" yaml:"idleTimeoutMinutes,omitempty"`
+	// ModelIdleTimeoutMinutes overrides IdleTimeoutMinutes by model id.
+	// A negative value keeps the model running.
+	ModelIdleTimeoutMinutes map[string]int `json:"modelIdleTimeoutMinutes,omitempty" yaml:"modelIdleTimeoutMinutes,omitempty"`
+}
+
+type PromptServiceConfig struct {
+	// HistoryMessageLimit is the maximum number of earlier messages
+	// of a thread sent to the model along with a prompt.
+	// Zero means the default limit, a negative value disables history.
+	HistoryMessageLimit int `json:"historyMessageLimit,omitempty" yaml:"historyMessageLimit,omitempty"`
+	// ContextStrategy is applied when the history does not fit the
+	// context window of the model. One of "drop-oldest" (the default),
+	// "keep-system-last-n" and "summarize".
+	ContextStrategy string `json:"contextStrategy,omitempty" yaml:"contextStrategy,omitempty"`
+	// KeepLastMessages is the N of the "keep-system-last-n" strategy
+	KeepLastMessages int `json:"keepLastMessages,omitempty" yaml:"keepLastMessages,omitempty"`
+	// ConcurrencyPerModel is the number of prompts a single model
+	// processes at the same time. Zero means one.
+	ConcurrencyPerModel int `json:"concurrencyPerModel,omitempty" yaml:"concurrencyPerModel,omitempty"`
 }
 
 type AppServiceConfig struct {
@@ -25,6 +48,7 @@ type AppServiceConfig struct {
 type Config struct {
 	Download DownloadServiceConfig `json:"download" yaml:"download"`
 	Model    ModelServiceConfig    `json:"model" yaml:"model"`
+	Prompt   PromptServiceConfig   `json:"prompt" yaml:"prompt"`
 	App      AppServiceConfig      `json:"app" yaml:"app"`
 
 	/** This flag drives a minor UX feature:
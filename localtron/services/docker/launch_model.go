@@ -14,7 +14,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -35,7 +34,9 @@ type LaunchOptions struct {
 
 type LaunchInfo struct {
 	NewContainerStarted bool
-	PortNumber          int
+	// PortNumber is the host port of the container. It is the requested one
+	// unless an already running container was reused.
+	PortNumber int
 }
 
 /*
@@ -98,15 +99,24 @@ func (d *DockerService) LaunchContainer(image string, internalPort, hostPort int
 
 	var existingContainer *types.Container
 	for _, container := range containers {
-		for _, name := range container.Names {
-			if name == "/"+options.Name || name == options.Name || strings.Contains(name, options.Name) {
-				existingContainer = &container
+		// a running container of the same hash can be reused whatever its name is
+		if container.State == "running" && container.Labels["singulatron-hash"] == options.Hash {
+			existingContainer = &container
+			break
+		}
+	}
+	if existingContainer == nil {
+		for _, container := range containers {
+			for _, name := range container.Names {
+				if name == "/"+options.Name || name == options.Name {
+					existingContainer = &container
+					break
+				}
+			}
+			if existingContainer != nil {
 				break
 			}
 		}
-		if existingContainer != nil {
-			break
-		}
 	}
 
 	if existingContainer != nil {
@@ -122,7 +132,7 @@ func (d *DockerService) LaunchContainer(image string, internalPort, hostPort int
 		} else {
 			return &LaunchInfo{
 				NewContainerStarted: false,
-				PortNumber:          hostPort,
+				PortNumber:          publicPort(existingContainer, hostPort),
 			}, nil
 		}
 	}
@@ -143,3 +153,12 @@ func (d *DockerService) LaunchContainer(image string, internalPort, hostPort int
 		PortNumber:          hostPort,
 	}, nil
 }
+
+func publicPort(c *types.Container, defaultPort int) int {
+	for _, port := range c.Ports {
+		if port.PublicPort != 0 {
+			return int(port.PublicPort)
+		}
+	}
+	return defaultPort
+}
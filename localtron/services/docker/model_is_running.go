@@ -35,3 +35,28 @@ func (d *DockerService) HashIsRunning(hash string) (bool, error) {
 
 	return false, nil
 }
+
+/*
+HashHostPort returns the host port of the running container with the given hash.
+Found is false when there is no such container or it publishes no port.
+*/
+func (d *DockerService) HashHostPort(hash string) (int, bool, error) {
+	ctx := context.Background()
+	containers, err := d.client.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return 0, false, errors.Wrap(err, "error listing docker containers when looking for port")
+	}
+
+	for _, container := range containers {
+		if container.State != "running" || container.Labels["singulatron-hash"] != hash {
+			continue
+		}
+		for _, port := range container.Ports {
+			if port.PublicPort != 0 {
+				return int(port.PublicPort), true, nil
+			}
+		}
+	}
+
+	return 0, false, nil
+}
@@ -0,0 +1,47 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package dockerservice
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+)
+
+/*
+StopContainer stops and removes the containers running the model with the given hash.
+Returns false when there was no such container.
+*/
+func (d *DockerService) StopContainer(hash string) (bool, error) {
+	d.launchModelMutex.Lock()
+	defer d.launchModelMutex.Unlock()
+
+	ctx := context.Background()
+	containers, err := d.client.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return false, errors.Wrap(err, "error listing docker containers when stopping")
+	}
+
+	stopped := false
+	for _, c := range containers {
+		if c.Labels["singulatron-hash"] != hash {
+			continue
+		}
+		err := d.client.ContainerRemove(ctx, c.ID, container.RemoveOptions{Force: true})
+		if err != nil {
+			return stopped, errors.Wrap(err, "error removing Docker container")
+		}
+		stopped = true
+	}
+
+	return stopped, nil
+}
@@ -0,0 +1,134 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documentservice
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	documenttypes "github.com/singulatron/singulatron/localtron/services/document/types"
+	embeddingtypes "github.com/singulatron/singulatron/localtron/services/embedding/types"
+)
+
+/*
+AddDocument chunks a document, embeds the chunks with the model
+of the collection and saves them to the collection.
+Embedding needs the model of the collection to be running.
+*/
+func (d *DocumentService) AddDocument(userId string, req *documenttypes.AddDocumentRequest) (*documenttypes.Document, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("%w: missing name", ErrInvalidDocument)
+	}
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = documenttypes.ContentTypeText
+	}
+	if contentType != documenttypes.ContentTypeText && contentType != documenttypes.ContentTypeMarkdown {
+		return nil, fmt.Errorf("%w: unsupported content type '%v'", ErrInvalidDocument, contentType)
+	}
+
+	chunks := chunkText(req.Content, contentType)
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("%w: empty content", ErrInvalidDocument)
+	}
+
+	d.ingestMutex.Lock()
+	defer d.ingestMutex.Unlock()
+
+	collection, err := d.ownCollection(userId, req.CollectionId)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &documenttypes.Document{
+		Id:           req.DocumentId,
+		CreatedAt:    time.Now(),
+		UserId:       userId,
+		CollectionId: collection.Id,
+		Name:         req.Name,
+		ContentType:  contentType,
+		Size:         len(req.Content),
+		ChunkCount:   len(chunks),
+	}
+	if document.Id == "" {
+		document.Id = uuid.New().String()
+	}
+
+	existing, found, err := d.documentsStore.Query(
+		datastore.Id(document.Id),
+	).FindOne()
+	if err != nil {
+		return nil, err
+	}
+	if found && (existing.UserId != userId || existing.CollectionId != collection.Id) {
+		return nil, ErrUnauthorized
+	}
+
+	items := []*embeddingtypes.EmbedItem{}
+	for i, chunk := range chunks {
+		items = append(items, &embeddingtypes.EmbedItem{
+			Id:   chunkId(document.Id, i),
+			Text: chunk,
+			Metadata: map[string]any{
+				"documentId": document.Id,
+				"chunk":      i,
+			},
+		})
+	}
+
+	embeddings, err := d.embeddingService.Embed(userId, &embeddingtypes.EmbedRequest{
+		ModelId:    collection.ModelId,
+		Collection: embeddingCollection(collection.Id),
+		Items:      items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if found && existing.ChunkCount > len(chunks) {
+		err = d.embeddingService.Delete(userId, chunkIds(document.Id, len(chunks), existing.ChunkCount))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if collection.ModelId == "" {
+		collection.ModelId = embeddings[0].ModelId
+		err = d.collectionsStore.Upsert(collection)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = d.documentsStore.Upsert(document)
+	if err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+func chunkId(documentId string, chunk int) string {
+	return fmt.Sprintf("%v:%v", documentId, chunk)
+}
+
+// chunkIds returns the ids of the chunks of a document from start up to end
+func chunkIds(documentId string, start, end int) []string {
+	ids := []string{}
+	for i := start; i < end; i++ {
+		ids = append(ids, chunkId(documentId, i))
+	}
+	return ids
+}
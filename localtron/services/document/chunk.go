@@ -0,0 +1,109 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documentservice
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	documenttypes "github.com/singulatron/singulatron/localtron/services/document/types"
+)
+
+const (
+	// chunkSize is the maximum length of a chunk in bytes, a few hundred tokens
+	chunkSize = 1500
+	// chunkOverlap is the maximum length of the last paragraph
+	// of a chunk repeated at the start of the next one
+	chunkOverlap = 300
+)
+
+/*
+chunkText splits a document into chunks of whole paragraphs.
+Paragraphs longer than chunkSize are split at word boundaries.
+Markdown headings start a new chunk so sections are embedded on their own.
+*/
+func chunkText(text string, contentType string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	markdown := contentType == documenttypes.ContentTypeMarkdown
+
+	paragraphs := []string{}
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		paragraphs = append(paragraphs, splitLong(paragraph, chunkSize)...)
+	}
+
+	chunks := []string{}
+	current := []string{}
+	currentLength := 0
+
+	flush := func(overlap bool) {
+		if len(current) == 0 {
+			return
+		}
+		chunks = append(chunks, strings.Join(current, "\n\n"))
+
+		last := current[len(current)-1]
+		current = []string{}
+		currentLength = 0
+		if overlap && len(last) <= chunkOverlap {
+			current = append(current, last)
+			currentLength = len(last)
+		}
+	}
+
+	for _, paragraph := range paragraphs {
+		if markdown && strings.HasPrefix(paragraph, "#") {
+			flush(false)
+		}
+		if currentLength > 0 && currentLength+len(paragraph)+2 > chunkSize {
+			flush(true)
+			// the overlap itself might not fit next to a long paragraph
+			if currentLength+len(paragraph)+2 > chunkSize {
+				current = []string{}
+				currentLength = 0
+			}
+		}
+
+		if currentLength > 0 {
+			currentLength += 2
+		}
+		current = append(current, paragraph)
+		currentLength += len(paragraph)
+	}
+	flush(false)
+
+	return chunks
+}
+
+// splitLong splits a text into parts of at most size bytes at spaces where possible
+func splitLong(text string, size int) []string {
+	parts := []string{}
+	for len(text) > size {
+		cut := strings.LastIndexAny(text[:size], " \n\t")
+		if cut <= 0 {
+			cut = size
+			// do not cut a multi-byte character in half
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		parts = append(parts, strings.TrimSpace(text[:cut]))
+		text = strings.TrimSpace(text[cut:])
+	}
+	if text != "" {
+		parts = append(parts, text)
+	}
+
+	return parts
+}
@@ -0,0 +1,62 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documentservice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	documenttypes "github.com/singulatron/singulatron/localtron/services/document/types"
+)
+
+func TestChunkText(t *testing.T) {
+	t.Run("Short text is one chunk", func(t *testing.T) {
+		chunks := chunkText("Restart the server.\r\n\r\nThen check the logs.", documenttypes.ContentTypeText)
+		assert.Equal(t, []string{"Restart the server.\n\nThen check the logs."}, chunks)
+	})
+
+	t.Run("Empty text has no chunks", func(t *testing.T) {
+		assert.Empty(t, chunkText(" \n\n \n", documenttypes.ContentTypeText))
+	})
+
+	t.Run("Markdown headings start a chunk", func(t *testing.T) {
+		text := "# Deploy\n\nRun make deploy.\n\n# Rollback\n\nRun make rollback."
+		chunks := chunkText(text, documenttypes.ContentTypeMarkdown)
+		assert.Equal(t, []string{
+			"# Deploy\n\nRun make deploy.",
+			"# Rollback\n\nRun make rollback.",
+		}, chunks)
+
+		chunks = chunkText(text, documenttypes.ContentTypeText)
+		assert.Equal(t, 1, len(chunks))
+	})
+
+	t.Run("Chunks overlap by a short paragraph", func(t *testing.T) {
+		long := strings.Repeat("a", 1000)
+		short := "short paragraph"
+		chunks := chunkText(long+"\n\n"+short+"\n\n"+long, documenttypes.ContentTypeText)
+		require.Equal(t, 2, len(chunks))
+		assert.Equal(t, long+"\n\n"+short, chunks[0])
+		assert.Equal(t, short+"\n\n"+long, chunks[1])
+	})
+
+	t.Run("Long paragraphs are split at spaces", func(t *testing.T) {
+		chunks := chunkText(strings.Repeat("word ", 1000), documenttypes.ContentTypeText)
+		require.Equal(t, 4, len(chunks))
+		for _, chunk := range chunks {
+			assert.LessOrEqual(t, len(chunk), chunkSize)
+			assert.False(t, strings.HasSuffix(chunk, "wor"))
+		}
+	})
+}
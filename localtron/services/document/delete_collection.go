@@ -0,0 +1,42 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documentservice
+
+import (
+	"github.com/singulatron/singulatron/localtron/datastore"
+)
+
+// DeleteCollection deletes a collection of the user with its documents and their embeddings
+func (d *DocumentService) DeleteCollection(userId string, collectionId string) error {
+	d.ingestMutex.Lock()
+	defer d.ingestMutex.Unlock()
+
+	_, err := d.ownCollection(userId, collectionId)
+	if err != nil {
+		return err
+	}
+
+	err = d.embeddingService.DeleteCollection(userId, embeddingCollection(collectionId))
+	if err != nil {
+		return err
+	}
+
+	err = d.documentsStore.Query(
+		datastore.Equal("collectionId", collectionId),
+	).Delete()
+	if err != nil {
+		return err
+	}
+
+	return d.collectionsStore.Query(
+		datastore.Id(collectionId),
+	).Delete()
+}
@@ -0,0 +1,43 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documentservice
+
+import (
+	"github.com/singulatron/singulatron/localtron/datastore"
+)
+
+// DeleteDocument deletes a document of the user and the embeddings of its chunks
+func (d *DocumentService) DeleteDocument(userId string, documentId string) error {
+	d.ingestMutex.Lock()
+	defer d.ingestMutex.Unlock()
+
+	document, found, err := d.documentsStore.Query(
+		datastore.Id(documentId),
+	).FindOne()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrDocumentNotFound
+	}
+	if document.UserId != userId {
+		return ErrUnauthorized
+	}
+
+	err = d.embeddingService.Delete(userId, chunkIds(document.Id, 0, document.ChunkCount))
+	if err != nil {
+		return err
+	}
+
+	return d.documentsStore.Query(
+		datastore.Id(documentId),
+	).Delete()
+}
@@ -0,0 +1,68 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documentservice
+
+import (
+	"sync"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+
+	configservice "github.com/singulatron/singulatron/localtron/services/config"
+	documenttypes "github.com/singulatron/singulatron/localtron/services/document/types"
+	embeddingservice "github.com/singulatron/singulatron/localtron/services/embedding"
+	storefactoryservice "github.com/singulatron/singulatron/localtron/services/store_factory"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+type DocumentService struct {
+	configService    *configservice.ConfigService
+	userService      *userservice.UserService
+	embeddingService *embeddingservice.EmbeddingService
+
+	collectionsStore datastore.DataStore[*documenttypes.Collection]
+	documentsStore   datastore.DataStore[*documenttypes.Document]
+
+	// ingestMutex serializes adding and deleting documents
+	// so a collection is embedded with one model
+	ingestMutex sync.Mutex
+}
+
+func NewDocumentService(
+	cs *configservice.ConfigService,
+	userService *userservice.UserService,
+	embeddingService *embeddingservice.EmbeddingService,
+) (*DocumentService, error) {
+	collectionsStore, err := storefactoryservice.GetStore[*documenttypes.Collection]("documentCollections")
+	if err != nil {
+		return nil, err
+	}
+
+	documentsStore, err := storefactoryservice.GetStore[*documenttypes.Document]("documents")
+	if err != nil {
+		return nil, err
+	}
+
+	service := &DocumentService{
+		configService:    cs,
+		userService:      userService,
+		embeddingService: embeddingService,
+
+		collectionsStore: collectionsStore,
+		documentsStore:   documentsStore,
+	}
+
+	err = service.registerPermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
@@ -0,0 +1,71 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documentendpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	documentservice "github.com/singulatron/singulatron/localtron/services/document"
+	documenttypes "github.com/singulatron/singulatron/localtron/services/document/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func DeleteCollection(
+	w http.ResponseWriter,
+	r *http.Request,
+	userService *userservice.UserService,
+	documentService *documentservice.DocumentService,
+) {
+	err := userService.IsAuthorized(documenttypes.PermissionDocumentEdit.Id, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	user, found, err := userService.GetUserFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if !found {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	req := &documenttypes.DeleteCollectionRequest{}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		http.Error(w, `invalid JSON`, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = documentService.DeleteCollection(user.Id, req.CollectionId)
+	switch {
+	case errors.Is(err, documentservice.ErrInvalidDocument):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, documentservice.ErrUnauthorized):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case errors.Is(err, documentservice.ErrCollectionNotFound), errors.Is(err, documentservice.ErrDocumentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bs, _ := json.Marshal(documenttypes.DeleteCollectionResponse{})
+	w.Write(bs)
+}
@@ -0,0 +1,52 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documentendpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	documentservice "github.com/singulatron/singulatron/localtron/services/document"
+	documenttypes "github.com/singulatron/singulatron/localtron/services/document/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func ListCollections(
+	w http.ResponseWriter,
+	r *http.Request,
+	userService *userservice.UserService,
+	documentService *documentservice.DocumentService,
+) {
+	err := userService.IsAuthorized(documenttypes.PermissionDocumentView.Id, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	req := &documenttypes.ListCollectionsRequest{}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		http.Error(w, `invalid JSON`, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	collections, err := documentService.ListCollections()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bs, _ := json.Marshal(documenttypes.ListCollectionsResponse{
+		Collections: collections,
+	})
+	w.Write(bs)
+}
@@ -0,0 +1,150 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documentservice
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	"github.com/singulatron/singulatron/localtron/logger"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	embeddingservice "github.com/singulatron/singulatron/localtron/services/embedding"
+	embeddingtypes "github.com/singulatron/singulatron/localtron/services/embedding/types"
+)
+
+// minRetrievalScore filters out passages unrelated to the query
+const minRetrievalScore = 0.2
+
+/*
+Retrieve returns the passages of the documents in the collections
+of the user most similar to a query as citations numbered from 1.
+Collections of other users are skipped, and so are the ones whose
+embedding model is not running as the query can't be embedded with their model.
+*/
+func (d *DocumentService) Retrieve(userId string, collectionIds []string, query string, limit int) ([]*chattypes.Citation, error) {
+	if len(collectionIds) == 0 {
+		return nil, nil
+	}
+
+	collections, err := d.collectionsStore.Query(
+		datastore.Equal("id", collectionIds),
+	).Find()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*embeddingtypes.SearchResult{}
+	collectionOf := map[*embeddingtypes.SearchResult]string{}
+	for _, collection := range collections {
+		if collection.UserId != userId {
+			logger.Warn("Skipping collection of an other user",
+				slog.String("collectionId", collection.Id),
+				slog.String("userId", userId),
+			)
+			continue
+		}
+		if collection.ModelId == "" {
+			// no documents yet
+			continue
+		}
+
+		found, err := d.embeddingService.Search(collection.UserId, &embeddingtypes.SearchRequest{
+			ModelId:    collection.ModelId,
+			Collection: embeddingCollection(collection.Id),
+			Query:      query,
+			Limit:      limit,
+			MinScore:   minRetrievalScore,
+		})
+		if errors.Is(err, embeddingservice.ErrModelNotRunning) {
+			logger.Warn("Skipping collection as its embedding model is not running",
+				slog.String("collectionId", collection.Id),
+				slog.String("modelId", collection.ModelId),
+			)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range found {
+			collectionOf[result] = collection.Id
+		}
+		results = append(results, found...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return d.citations(results, collectionOf)
+}
+
+func (d *DocumentService) citations(
+	results []*embeddingtypes.SearchResult,
+	collectionOf map[*embeddingtypes.SearchResult]string,
+) ([]*chattypes.Citation, error) {
+	documentIds := []string{}
+	for _, result := range results {
+		documentIds = append(documentIds, metadataString(result.Embedding.Metadata, "documentId"))
+	}
+	if len(documentIds) == 0 {
+		return nil, nil
+	}
+
+	documents, err := d.documentsStore.Query(
+		datastore.Equal("id", documentIds),
+	).Find()
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]string{}
+	for _, document := range documents {
+		names[document.Id] = document.Name
+	}
+
+	citations := []*chattypes.Citation{}
+	for i, result := range results {
+		documentId := metadataString(result.Embedding.Metadata, "documentId")
+		citations = append(citations, &chattypes.Citation{
+			Index:        i + 1,
+			CollectionId: collectionOf[result],
+			DocumentId:   documentId,
+			DocumentName: names[documentId],
+			Chunk:        metadataInt(result.Embedding.Metadata, "chunk"),
+			Text:         result.Embedding.Text,
+			Score:        result.Score,
+		})
+	}
+
+	return citations, nil
+}
+
+func metadataString(metadata map[string]any, key string) string {
+	v, _ := metadata[key].(string)
+	return v
+}
+
+// metadataInt reads numbers which might have been decoded from JSON as floats
+func metadataInt(metadata map[string]any, key string) int {
+	switch v := metadata[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
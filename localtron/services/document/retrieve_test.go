@@ -0,0 +1,42 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documentservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/singulatron/singulatron/localtron/datastore/localstore"
+	documenttypes "github.com/singulatron/singulatron/localtron/services/document/types"
+)
+
+func TestRetrieveOtherUsersCollection(t *testing.T) {
+	d := &DocumentService{
+		collectionsStore: localstore.NewLocalStore[*documenttypes.Collection](""),
+		documentsStore:   localstore.NewLocalStore[*documenttypes.Document](""),
+	}
+	collection, err := d.SaveCollection("owner", &documenttypes.Collection{
+		Name: "Private notes",
+	})
+	require.NoError(t, err)
+	collection.ModelId = "embedding-model"
+	require.NoError(t, d.collectionsStore.Upsert(collection))
+
+	// the embedding service is never reached for collections of others
+	citations, err := d.Retrieve("other", []string{collection.Id}, "notes", 4)
+	require.NoError(t, err)
+	require.Empty(t, citations)
+
+	require.ErrorIs(t, d.CheckCollections("other", []string{collection.Id}), ErrUnauthorized)
+	require.ErrorIs(t, d.CheckCollections("owner", []string{"missing"}), ErrCollectionNotFound)
+	require.NoError(t, d.CheckCollections("owner", []string{collection.Id}))
+}
@@ -0,0 +1,96 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documentservice
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	documenttypes "github.com/singulatron/singulatron/localtron/services/document/types"
+)
+
+var (
+	ErrCollectionNotFound = errors.New("collection not found")
+	ErrDocumentNotFound   = errors.New("document not found")
+	ErrInvalidDocument    = errors.New("invalid document")
+	ErrUnauthorized       = errors.New("unauthorized")
+)
+
+/*
+SaveCollection creates or updates a collection.
+Collections can be read by everyone but only changed by their owner.
+The embedding model of a collection can't be changed once it has documents.
+*/
+func (d *DocumentService) SaveCollection(userId string, collection *documenttypes.Collection) (*documenttypes.Collection, error) {
+	if strings.TrimSpace(collection.Name) == "" {
+		return nil, fmt.Errorf("%w: missing name", ErrInvalidDocument)
+	}
+
+	now := time.Now()
+	collection.UpdatedAt = now
+
+	if collection.Id == "" {
+		collection.Id = uuid.New().String()
+	}
+
+	existing, found, err := d.collectionsStore.Query(
+		datastore.Id(collection.Id),
+	).FindOne()
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		if existing.UserId != userId {
+			return nil, ErrUnauthorized
+		}
+		collection.CreatedAt = existing.CreatedAt
+		collection.UserId = existing.UserId
+		collection.ModelId = existing.ModelId
+	} else {
+		collection.CreatedAt = now
+		collection.UserId = userId
+	}
+
+	err = d.collectionsStore.Upsert(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// ownCollection returns a collection of the user
+func (d *DocumentService) ownCollection(userId string, collectionId string) (*documenttypes.Collection, error) {
+	collection, found, err := d.collectionsStore.Query(
+		datastore.Id(collectionId),
+	).FindOne()
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrCollectionNotFound
+	}
+	if collection.UserId != userId {
+		return nil, ErrUnauthorized
+	}
+
+	return collection, nil
+}
+
+// embeddingCollection is the embedding collection the chunks of a document collection are saved to
+func embeddingCollection(collectionId string) string {
+	return "document:" + collectionId
+}
@@ -0,0 +1,103 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package documenttypes
+
+import (
+	"time"
+)
+
+// Collection is a set of documents threads can be answered from
+type Collection struct {
+	Id          string    `json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	UserId      string    `json:"userId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	// ModelId is the model the documents of the collection are embedded with.
+	// It is set when the first document is added, defaulting to the current model.
+	ModelId string `json:"modelId,omitempty"`
+}
+
+func (c *Collection) GetId() string {
+	return c.Id
+}
+
+const (
+	ContentTypeText     = "text/plain"
+	ContentTypeMarkdown = "text/markdown"
+)
+
+type Document struct {
+	Id           string    `json:"id"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UserId       string    `json:"userId"`
+	CollectionId string    `json:"collectionId"`
+	Name         string    `json:"name"`
+	// ContentType is text/plain or text/markdown.
+	// PDFs and other formats are expected to be converted to text before upload.
+	ContentType string `json:"contentType"`
+	// Size is the length of the content in bytes
+	Size       int `json:"size"`
+	ChunkCount int `json:"chunkCount"`
+}
+
+func (d *Document) GetId() string {
+	return d.Id
+}
+
+type SaveCollectionRequest struct {
+	Collection *Collection `json:"collection"`
+}
+
+type SaveCollectionResponse struct {
+	Collection *Collection `json:"collection"`
+}
+
+type ListCollectionsRequest struct{}
+
+type ListCollectionsResponse struct {
+	Collections []*Collection `json:"collections"`
+}
+
+type DeleteCollectionRequest struct {
+	CollectionId string `json:"collectionId"`
+}
+
+type DeleteCollectionResponse struct{}
+
+type AddDocumentRequest struct {
+	CollectionId string `json:"collectionId"`
+	// DocumentId is optional, adding a document with
+	// the same id again replaces the earlier one
+	DocumentId  string `json:"documentId,omitempty"`
+	Name        string `json:"name"`
+	ContentType string `json:"contentType,omitempty"`
+	Content     string `json:"content"`
+}
+
+type AddDocumentResponse struct {
+	Document *Document `json:"document"`
+}
+
+type ListDocumentsRequest struct {
+	CollectionId string `json:"collectionId"`
+}
+
+type ListDocumentsResponse struct {
+	Documents []*Document `json:"documents"`
+}
+
+type DeleteDocumentRequest struct {
+	DocumentId string `json:"documentId"`
+}
+
+type DeleteDocumentResponse struct{}
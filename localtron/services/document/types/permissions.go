@@ -0,0 +1,31 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+
+package documenttypes
+
+import (
+	usertypes "github.com/singulatron/singulatron/localtron/services/user/types"
+)
+
+var PermissionDocumentView = usertypes.Permission{
+	Id:   "document.view",
+	Name: "Document View",
+}
+
+var PermissionDocumentEdit = usertypes.Permission{
+	Id:   "document.edit",
+	Name: "Document Edit",
+}
+
+var DocumentPermissions = []usertypes.Permission{
+	PermissionDocumentView,
+	PermissionDocumentEdit,
+}
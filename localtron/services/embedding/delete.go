@@ -0,0 +1,35 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package embeddingservice
+
+import (
+	"github.com/singulatron/singulatron/localtron/datastore"
+)
+
+// Delete deletes the embeddings of the user with the given ids
+func (e *EmbeddingService) Delete(userId string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return e.embeddingsStore.Query(
+		datastore.Equal("userId", userId),
+		datastore.Equal("id", ids),
+	).Delete()
+}
+
+// DeleteCollection deletes all embeddings of a collection of the user
+func (e *EmbeddingService) DeleteCollection(userId string, collection string) error {
+	return e.embeddingsStore.Query(
+		datastore.Equal("userId", userId),
+		datastore.Equal("collection", collection),
+	).Delete()
+}
@@ -0,0 +1,182 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package embeddingservice
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/clients/llm"
+	"github.com/singulatron/singulatron/localtron/datastore"
+	embeddingtypes "github.com/singulatron/singulatron/localtron/services/embedding/types"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+var (
+	ErrInvalidRequest  = errors.New("invalid request")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrModelNotRunning = errors.New("model is not running")
+)
+
+// embedBatchSize is the number of texts embedded in one request to the model
+const embedBatchSize = 32
+
+// Embed returns the embeddings of the items of a request
+// and saves them when the request has a collection.
+func (e *EmbeddingService) Embed(userId string, req *embeddingtypes.EmbedRequest) ([]*embeddingtypes.Embedding, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: no items", ErrInvalidRequest)
+	}
+
+	texts := []string{}
+	for i, item := range req.Items {
+		if strings.TrimSpace(item.Text) == "" {
+			return nil, fmt.Errorf("%w: item %d has no text", ErrInvalidRequest, i)
+		}
+		texts = append(texts, item.Text)
+	}
+
+	modelId, vectors, err := e.Vectors(req.ModelId, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	embeddings := []*embeddingtypes.Embedding{}
+	for i, item := range req.Items {
+		id := item.Id
+		if id == "" {
+			id = uuid.New().String()
+		}
+		embeddings = append(embeddings, &embeddingtypes.Embedding{
+			Id:         id,
+			CreatedAt:  now,
+			UserId:     userId,
+			Collection: req.Collection,
+			ModelId:    modelId,
+			Text:       item.Text,
+			Metadata:   item.Metadata,
+			Vector:     vectors[i],
+		})
+	}
+
+	if req.Collection == "" {
+		return embeddings, nil
+	}
+
+	err = e.save(userId, embeddings)
+	if err != nil {
+		return nil, err
+	}
+
+	return embeddings, nil
+}
+
+func (e *EmbeddingService) save(userId string, embeddings []*embeddingtypes.Embedding) error {
+	ids := []string{}
+	for _, embedding := range embeddings {
+		ids = append(ids, embedding.Id)
+	}
+
+	e.saveMutex.Lock()
+	defer e.saveMutex.Unlock()
+
+	existing, err := e.embeddingsStore.Query(
+		datastore.Equal("id", ids),
+	).Find()
+	if err != nil {
+		return err
+	}
+	for _, embedding := range existing {
+		if embedding.UserId != userId {
+			return fmt.Errorf("%w: embedding '%v' belongs to another user", ErrUnauthorized, embedding.Id)
+		}
+	}
+
+	return e.embeddingsStore.UpsertMany(embeddings)
+}
+
+/*
+Vectors returns the normalized embedding vectors of texts
+and the id of the model that made them.
+The model defaults to the current model and must be running.
+*/
+func (e *EmbeddingService) Vectors(modelId string, texts []string) (string, [][]float64, error) {
+	modelId, err := e.resolveModel(modelId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	platform, err := e.modelService.GetPlatformByModelId(modelId)
+	if err != nil {
+		return "", nil, err
+	}
+	if platform.Id != modeltypes.PlatformIdLlamaCpp {
+		return "", nil, fmt.Errorf("%w: model '%v' can't make embeddings", ErrInvalidRequest, modelId)
+	}
+
+	stat, err := e.modelService.Status(modelId)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "error getting model status")
+	}
+	if !stat.Running || stat.Address == "" {
+		return "", nil, fmt.Errorf("%w: '%v'", ErrModelNotRunning, modelId)
+	}
+	address := stat.Address
+	if !strings.HasPrefix(address, "http") {
+		address = "http://" + address
+	}
+
+	client := llm.Client{
+		LLMAddress: address,
+	}
+
+	release := e.modelService.Use(modelId)
+	defer release()
+
+	vectors := [][]float64{}
+	for start := 0; start < len(texts); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(texts))
+
+		rsp, err := client.PostEmbeddings(llm.EmbeddingsRequest{
+			Input: texts[start:end],
+		})
+		if err != nil {
+			return "", nil, errors.Wrap(err, "error embedding texts")
+		}
+
+		for _, data := range rsp.Data {
+			vectors = append(vectors, normalize(data.Embedding))
+		}
+	}
+
+	return modelId, vectors, nil
+}
+
+func (e *EmbeddingService) resolveModel(modelId string) (string, error) {
+	if modelId != "" {
+		return modelId, nil
+	}
+
+	conf, err := e.configService.GetConfig()
+	if err != nil {
+		return "", err
+	}
+	if conf.Model.CurrentModelId == "" {
+		return "", fmt.Errorf("%w: no model id specified and no default model", ErrInvalidRequest)
+	}
+
+	return conf.Model.CurrentModelId, nil
+}
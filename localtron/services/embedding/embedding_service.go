@@ -0,0 +1,60 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package embeddingservice
+
+import (
+	"sync"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+
+	configservice "github.com/singulatron/singulatron/localtron/services/config"
+	embeddingtypes "github.com/singulatron/singulatron/localtron/services/embedding/types"
+	modelservice "github.com/singulatron/singulatron/localtron/services/model"
+	storefactoryservice "github.com/singulatron/singulatron/localtron/services/store_factory"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+type EmbeddingService struct {
+	configService *configservice.ConfigService
+	userService   *userservice.UserService
+	modelService  *modelservice.ModelService
+
+	embeddingsStore datastore.DataStore[*embeddingtypes.Embedding]
+
+	// saveMutex guards the ownership check and the upsert of embeddings
+	saveMutex sync.Mutex
+}
+
+func NewEmbeddingService(
+	cs *configservice.ConfigService,
+	userService *userservice.UserService,
+	modelService *modelservice.ModelService,
+) (*EmbeddingService, error) {
+	embeddingsStore, err := storefactoryservice.GetStore[*embeddingtypes.Embedding]("embeddings")
+	if err != nil {
+		return nil, err
+	}
+
+	service := &EmbeddingService{
+		configService: cs,
+		userService:   userService,
+		modelService:  modelService,
+
+		embeddingsStore: embeddingsStore,
+	}
+
+	err = service.registerPermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
@@ -0,0 +1,73 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package embeddingendpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	embeddingservice "github.com/singulatron/singulatron/localtron/services/embedding"
+	embeddingtypes "github.com/singulatron/singulatron/localtron/services/embedding/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func Embed(
+	w http.ResponseWriter,
+	r *http.Request,
+	userService *userservice.UserService,
+	embeddingService *embeddingservice.EmbeddingService,
+) {
+	err := userService.IsAuthorized(embeddingtypes.PermissionEmbeddingCreate.Id, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	user, found, err := userService.GetUserFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if !found {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	req := &embeddingtypes.EmbedRequest{}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		http.Error(w, `invalid JSON`, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	embeddings, err := embeddingService.Embed(user.Id, req)
+	switch {
+	case errors.Is(err, embeddingservice.ErrInvalidRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, embeddingservice.ErrUnauthorized):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case errors.Is(err, embeddingservice.ErrModelNotRunning):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bs, _ := json.Marshal(embeddingtypes.EmbedResponse{
+		Embeddings: embeddings,
+	})
+	w.Write(bs)
+}
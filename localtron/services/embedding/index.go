@@ -0,0 +1,85 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package embeddingservice
+
+import (
+	"math"
+	"sort"
+
+	embeddingtypes "github.com/singulatron/singulatron/localtron/services/embedding/types"
+)
+
+func normalize(vector []float64) []float64 {
+	norm := 0.0
+	for _, v := range vector {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+
+	normalized := make([]float64, len(vector))
+	if norm == 0 {
+		return normalized
+	}
+	for i, v := range vector {
+		normalized[i] = v / norm
+	}
+
+	return normalized
+}
+
+// cosine expects normalized vectors of the same length
+func cosine(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+/*
+nearest is a brute-force search of the embeddings most similar to a vector.
+Collections are small enough (the messages of a thread, the chunks
+of a few documents) for this to beat the upkeep of an approximate index.
+Embeddings of vectors with a different length are skipped.
+*/
+func nearest(
+	embeddings []*embeddingtypes.Embedding,
+	vector []float64,
+	limit int,
+	minScore float64,
+) []*embeddingtypes.SearchResult {
+	results := []*embeddingtypes.SearchResult{}
+	for _, embedding := range embeddings {
+		if len(embedding.Vector) != len(vector) {
+			continue
+		}
+
+		score := cosine(embedding.Vector, vector)
+		if score < minScore {
+			continue
+		}
+
+		results = append(results, &embeddingtypes.SearchResult{
+			Embedding: embedding,
+			Score:     score,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
@@ -0,0 +1,49 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package embeddingservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	embeddingtypes "github.com/singulatron/singulatron/localtron/services/embedding/types"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, []float64{0.6, 0.8}, normalize([]float64{3, 4}))
+	assert.Equal(t, []float64{0, 0}, normalize([]float64{0, 0}))
+}
+
+func TestNearest(t *testing.T) {
+	embeddings := []*embeddingtypes.Embedding{
+		{Id: "cats", Vector: normalize([]float64{1, 0.1})},
+		{Id: "dogs", Vector: normalize([]float64{0.1, 1})},
+		{Id: "pets", Vector: normalize([]float64{1, 1})},
+		{Id: "other model", Vector: normalize([]float64{1, 0, 0})},
+	}
+	query := normalize([]float64{1, 0})
+
+	results := nearest(embeddings, query, 10, 0)
+	require.Equal(t, 3, len(results))
+	assert.Equal(t, "cats", results[0].Embedding.Id)
+	assert.Equal(t, "pets", results[1].Embedding.Id)
+	assert.Equal(t, "dogs", results[2].Embedding.Id)
+	assert.InDelta(t, 0.995, results[0].Score, 0.001)
+
+	results = nearest(embeddings, query, 1, 0)
+	require.Equal(t, 1, len(results))
+	assert.Equal(t, "cats", results[0].Embedding.Id)
+
+	results = nearest(embeddings, query, 10, 0.5)
+	require.Equal(t, 2, len(results))
+}
@@ -0,0 +1,73 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package embeddingservice
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	embeddingtypes "github.com/singulatron/singulatron/localtron/services/embedding/types"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 100
+)
+
+// Search returns the embeddings of a collection of the user most similar to the query.
+func (e *EmbeddingService) Search(userId string, req *embeddingtypes.SearchRequest) ([]*embeddingtypes.SearchResult, error) {
+	if req.Collection == "" {
+		return nil, fmt.Errorf("%w: missing collection", ErrInvalidRequest)
+	}
+	if strings.TrimSpace(req.Query) == "" && len(req.Vector) == 0 {
+		return nil, fmt.Errorf("%w: missing query", ErrInvalidRequest)
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+
+	modelId, err := e.resolveModel(req.ModelId)
+	if err != nil {
+		return nil, err
+	}
+
+	vector := normalize(req.Vector)
+	if len(req.Vector) == 0 {
+		var vectors [][]float64
+		modelId, vectors, err = e.Vectors(modelId, []string{req.Query})
+		if err != nil {
+			return nil, err
+		}
+		vector = vectors[0]
+	}
+
+	embeddings, err := e.embeddingsStore.Query(
+		datastore.Equal("userId", userId),
+		datastore.Equal("collection", req.Collection),
+		datastore.Equal("modelId", modelId),
+	).Find()
+	if err != nil {
+		return nil, err
+	}
+
+	results := nearest(embeddings, vector, limit, req.MinScore)
+	for _, result := range results {
+		embedding := *result.Embedding
+		embedding.Vector = nil
+		result.Embedding = &embedding
+	}
+
+	return results, nil
+}
@@ -0,0 +1,83 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package embeddingtypes
+
+import (
+	"time"
+)
+
+/*
+Embedding is the vector of a text.
+Embeddings in the same collection are searched together,
+eg. the messages of a thread or the chunks of a document.
+*/
+type Embedding struct {
+	Id         string    `json:"id"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UserId     string    `json:"userId"`
+	Collection string    `json:"collection"`
+	// ModelId is the model which made the vector.
+	// Vectors of different models can't be compared.
+	ModelId  string         `json:"modelId"`
+	Text     string         `json:"text"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	// Vector is normalized to unit length
+	Vector []float64 `json:"vector,omitempty"`
+}
+
+func (e *Embedding) GetId() string {
+	return e.Id
+}
+
+type EmbedItem struct {
+	// Id is optional, embedding a text with the same id
+	// again replaces the earlier embedding
+	Id       string         `json:"id,omitempty"`
+	Text     string         `json:"text"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+type EmbedRequest struct {
+	// ModelId defaults to the current model
+	ModelId string `json:"modelId,omitempty"`
+	// Collection to save the embeddings to.
+	// The embeddings are not saved when empty.
+	Collection string       `json:"collection,omitempty"`
+	Items      []*EmbedItem `json:"items"`
+}
+
+type EmbedResponse struct {
+	Embeddings []*Embedding `json:"embeddings"`
+}
+
+type SearchRequest struct {
+	// ModelId defaults to the current model
+	ModelId    string `json:"modelId,omitempty"`
+	Collection string `json:"collection"`
+	// Query is embedded and compared to the embeddings of the collection.
+	// Vector can be used instead of Query to search with a known vector.
+	Query  string    `json:"query,omitempty"`
+	Vector []float64 `json:"vector,omitempty"`
+	// Limit defaults to 10
+	Limit int `json:"limit,omitempty"`
+	// MinScore is the minimum cosine similarity of results
+	MinScore float64 `json:"minScore,omitempty"`
+}
+
+type SearchResult struct {
+	Embedding *Embedding `json:"embedding"`
+	// Score is the cosine similarity of the embedding and the query
+	Score float64 `json:"score"`
+}
+
+type SearchResponse struct {
+	Results []*SearchResult `json:"results"`
+}
@@ -0,0 +1,185 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package modelservice
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+var ErrInvalidCatalog = errors.New("invalid model catalog")
+
+// isCatalogFile tells if a file is a catalog by its extension
+func isCatalogFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+/*
+parseCatalog parses a YAML or JSON catalog file and validates it.
+Both formats use the JSON field names of the platforms and models,
+unknown fields are rejected so typos don't go unnoticed.
+*/
+func parseCatalog(name string, data []byte) (*modeltypes.Catalog, error) {
+	if strings.ToLower(filepath.Ext(name)) != ".json" {
+		var doc interface{}
+		err := yaml.Unmarshal(data, &doc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidCatalog, name, err)
+		}
+		data, err = json.Marshal(jsonCompatible(doc))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidCatalog, name, err)
+		}
+	}
+
+	catalog := &modeltypes.Catalog{}
+	if len(bytes.TrimSpace(data)) == 0 || string(bytes.TrimSpace(data)) == "null" {
+		return catalog, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(catalog)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidCatalog, name, err)
+	}
+
+	err = validateCatalog(catalog)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return catalog, nil
+}
+
+// jsonCompatible converts the maps decoded by yaml.v2 to maps with string keys
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonCompatible(value)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonCompatible(v[i])
+		}
+	}
+	return v
+}
+
+// validateCatalog checks the entries of a single catalog file
+func validateCatalog(catalog *modeltypes.Catalog) error {
+	platformIds := map[string]bool{}
+	for i, platform := range catalog.Platforms {
+		if platform == nil || platform.Id == "" {
+			return fmt.Errorf("%w: platform #%v is missing an id", ErrInvalidCatalog, i)
+		}
+		if platformIds[platform.Id] {
+			return fmt.Errorf("%w: duplicate platform '%v'", ErrInvalidCatalog, platform.Id)
+		}
+		platformIds[platform.Id] = true
+
+		if platform.Architectures.Default.Image == "" {
+			return fmt.Errorf("%w: platform '%v' is missing a default image", ErrInvalidCatalog, platform.Id)
+		}
+		for _, container := range []modeltypes.Container{
+			platform.Architectures.Default,
+			platform.Architectures.Cuda,
+		} {
+			if container.Image != "" && container.Port <= 0 {
+				return fmt.Errorf("%w: platform '%v' has an invalid port", ErrInvalidCatalog, platform.Id)
+			}
+		}
+	}
+
+	modelIds := map[string]bool{}
+	for i, model := range catalog.Models {
+		if model == nil || model.Id == "" {
+			return fmt.Errorf("%w: model #%v is missing an id", ErrInvalidCatalog, i)
+		}
+		if modelIds[model.Id] {
+			return fmt.Errorf("%w: duplicate model '%v'", ErrInvalidCatalog, model.Id)
+		}
+		modelIds[model.Id] = true
+
+		if model.PlatformId == "" {
+			return fmt.Errorf("%w: model '%v' is missing a platform id", ErrInvalidCatalog, model.Id)
+		}
+		if model.Name == "" {
+			return fmt.Errorf("%w: model '%v' is missing a name", ErrInvalidCatalog, model.Id)
+		}
+		for envarName, assetUrl := range model.Assets {
+			if envarName == "" || !strings.HasPrefix(assetUrl, "http://") && !strings.HasPrefix(assetUrl, "https://") {
+				return fmt.Errorf("%w: model '%v' has an invalid asset '%v'", ErrInvalidCatalog, model.Id, envarName)
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+mergeCatalogs merges catalogs in order of precedence: entries of a later
+catalog replace the entries of earlier ones with the same id.
+Models of unknown platforms are left out.
+*/
+func mergeCatalogs(catalogs ...*modeltypes.Catalog) (*modeltypes.Catalog, []string) {
+	ret := &modeltypes.Catalog{}
+	platformIndex := map[string]int{}
+	modelIndex := map[string]int{}
+
+	for _, catalog := range catalogs {
+		for _, platform := range catalog.Platforms {
+			if i, ok := platformIndex[platform.Id]; ok {
+				ret.Platforms[i] = platform
+				continue
+			}
+			platformIndex[platform.Id] = len(ret.Platforms)
+			ret.Platforms = append(ret.Platforms, platform)
+		}
+
+		for _, model := range catalog.Models {
+			if i, ok := modelIndex[model.Id]; ok {
+				ret.Models[i] = model
+				continue
+			}
+			modelIndex[model.Id] = len(ret.Models)
+			ret.Models = append(ret.Models, model)
+		}
+	}
+
+	models := ret.Models[:0]
+	skipped := []string{}
+	for _, model := range ret.Models {
+		if _, ok := platformIndex[model.PlatformId]; !ok {
+			skipped = append(skipped, model.Id)
+			continue
+		}
+		models = append(models, model)
+	}
+	ret.Models = models
+
+	return ret, skipped
+}
@@ -0,0 +1,149 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package modelservice
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+const yamlCatalog = `
+models:
+  - id: internal/finetuned.Q4_K_M.gguf
+    platformId: llama-cpp
+    name: Finetuned
+    full_name: Finetuned Q4_K_M
+    assets:
+      MODEL: https://example.com/finetuned.Q4_K_M.gguf
+    context_length: 4096
+    role_templates:
+      user: "[INST] {prompt} [/INST]"
+`
+
+func TestParseCatalog(t *testing.T) {
+	catalog, err := parseCatalog("internal.yaml", []byte(yamlCatalog))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(catalog.Models))
+	model := catalog.Models[0]
+	require.Equal(t, "Finetuned Q4_K_M", model.FullName)
+	require.Equal(t, 4096, model.ContextLength)
+	require.Equal(t, "[INST] {prompt} [/INST]", model.RoleTemplates.User)
+
+	catalog, err = parseCatalog("internal.json", []byte(`{"platforms": [{"id": "custom", "architectures": {"default": {"port": 8000, "image": "custom/image"}}}]}`))
+	require.NoError(t, err)
+	require.Equal(t, "custom/image", catalog.Platforms[0].Architectures.Default.Image)
+
+	catalog, err = parseCatalog("empty.yaml", []byte("# nothing yet\n"))
+	require.NoError(t, err)
+	require.Equal(t, 0, len(catalog.Models))
+
+	for name, content := range map[string]string{
+		"unknown field":  "models:\n  - id: a\n    platformId: llama-cpp\n    name: A\n    fullName: A\n",
+		"missing name":   "models:\n  - id: a\n    platformId: llama-cpp\n",
+		"duplicate":      "models:\n  - {id: a, platformId: llama-cpp, name: A}\n  - {id: a, platformId: llama-cpp, name: A}\n",
+		"invalid asset":  "models:\n  - {id: a, platformId: llama-cpp, name: A, assets: {MODEL: finetuned.gguf}}\n",
+		"missing image":  "platforms:\n  - id: custom\n",
+		"malformed yaml": "models: [",
+	} {
+		_, err := parseCatalog("catalog.yaml", []byte(content))
+		require.ErrorIs(t, err, ErrInvalidCatalog, name)
+	}
+}
+
+func TestBundledCatalogs(t *testing.T) {
+	catalogs, err := readBundledCatalogs()
+	require.NoError(t, err)
+	require.NotEqual(t, 0, len(catalogs))
+
+	catalog, skipped := mergeCatalogs(catalogs...)
+	require.Equal(t, 0, len(skipped))
+	require.NotEqual(t, 0, len(catalog.Models))
+
+	platformIds := []string{}
+	for _, platform := range catalog.Platforms {
+		platformIds = append(platformIds, platform.Id)
+	}
+	require.ElementsMatch(t, []string{
+		modeltypes.PlatformIdLlamaCpp,
+		modeltypes.PlatformIdStableDiffusion,
+	}, platformIds, "the platforms the code knows about are bundled")
+}
+
+func TestMergeCatalogs(t *testing.T) {
+	bundled := &modeltypes.Catalog{
+		Platforms: []*modeltypes.Platform{{Id: "llama-cpp"}},
+		Models: []*modeltypes.Model{
+			{Id: "a", PlatformId: "llama-cpp", Name: "A"},
+			{Id: "b", PlatformId: "llama-cpp", Name: "B"},
+		},
+	}
+	custom := &modeltypes.Catalog{
+		Models: []*modeltypes.Model{
+			{Id: "b", PlatformId: "llama-cpp", Name: "B2"},
+			{Id: "c", PlatformId: "llama-cpp", Name: "C"},
+			{Id: "d", PlatformId: "missing", Name: "D"},
+		},
+	}
+
+	catalog, skipped := mergeCatalogs(bundled, custom)
+	require.Equal(t, []string{"d"}, skipped)
+	require.Equal(t, 1, len(catalog.Platforms))
+
+	names := []string{}
+	for _, model := range catalog.Models {
+		names = append(names, model.Name)
+	}
+	require.Equal(t, []string{"A", "B2", "C"}, names)
+}
+
+func TestReadCatalogFolder(t *testing.T) {
+	dir := t.TempDir()
+	ms := &ModelService{
+		catalogDirectory: dir,
+		catalogFiles:     map[string]*catalogFile{},
+	}
+	filePath := path.Join(dir, "internal.yaml")
+
+	changed, err := ms.readCatalogFolder()
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	require.NoError(t, os.WriteFile(filePath, []byte(yamlCatalog), 0644))
+	require.NoError(t, os.WriteFile(path.Join(dir, "notes.txt"), []byte("not a catalog"), 0644))
+	changed, err = ms.readCatalogFolder()
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, 1, len(ms.catalogFiles))
+
+	changed, err = ms.readCatalogFolder()
+	require.NoError(t, err)
+	require.False(t, changed, "unmodified files are not read again")
+
+	require.NoError(t, os.WriteFile(filePath, []byte("models: ["), 0644))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(filePath, later, later))
+	changed, err = ms.readCatalogFolder()
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, 1, len(ms.catalogFiles[filePath].catalog.Models), "invalid file keeps its last valid content")
+
+	require.NoError(t, os.Remove(filePath))
+	changed, err = ms.readCatalogFolder()
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, 0, len(ms.catalogFiles))
+}
@@ -39,7 +39,12 @@ func Status(
 	}
 	defer r.Body.Close()
 
-	status, err := ms.Status(req.Url)
+	modelId := req.ModelId
+	if modelId == "" {
+		modelId = req.Url
+	}
+
+	status, err := ms.Status(modelId)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -21,3 +21,9 @@ func (ms *ModelService) GetModels() ([]*modeltypes.Model, error) {
 	).Find()
 
 }
+
+func (ms *ModelService) GetModel(modelId string) (*modeltypes.Model, bool, error) {
+	return ms.modelsStore.Query(
+		datastore.Id(modelId),
+	).FindOne()
+}
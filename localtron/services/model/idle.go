@@ -0,0 +1,165 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package modelservice
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/singulatron/singulatron/localtron/logger"
+
+	configtypes "github.com/singulatron/singulatron/localtron/services/config/types"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+const idleCheckInterval = time.Minute
+
+/*
+Use marks a model as being used by a prompt until the returned function
+is called, so it does not get unloaded for being idle meanwhile.
+Waits for the container of the model to stop if it is being unloaded.
+*/
+func (ms *ModelService) Use(modelId string) func() {
+	ms.waitForStop(modelId)
+	state := ms.get(modelId)
+
+	state.Lock()
+	state.InUse++
+	state.LastUsedAt = time.Now()
+	state.Unlock()
+
+	return func() {
+		state.Lock()
+		state.InUse--
+		state.LastUsedAt = time.Now()
+		state.Unlock()
+	}
+}
+
+// unloadIdleModels periodically stops the models idle for longer than their timeout
+func (ms *ModelService) unloadIdleModels() {
+	for {
+		time.Sleep(idleCheckInterval)
+
+		conf, err := ms.configService.GetConfig()
+		if err != nil {
+			logger.Warn("Cannot get config to unload idle models", slog.String("error", err.Error()))
+			continue
+		}
+
+		now := time.Now()
+		for _, modelId := range ms.idleModels(conf.Model, now) {
+			// the model might have been picked up by a prompt since
+			if !ms.claimIdle(modelId, idleTimeout(conf.Model, modelId), now) {
+				continue
+			}
+
+			logger.Info("Unloading idle model", slog.String("modelId", modelId))
+
+			err := ms.stopContainer(modelId, modeltypes.UnloadReasonIdle)
+			ms.finishStop(modelId)
+			if err != nil {
+				logger.Warn("Error unloading idle model",
+					slog.String("modelId", modelId),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+	}
+}
+
+// idleModels returns the started models which have not been used for longer than their timeout
+func (ms *ModelService) idleModels(conf configtypes.ModelServiceConfig, now time.Time) []string {
+	ms.modelStateMutex.Lock()
+	defer ms.modelStateMutex.Unlock()
+
+	ret := []string{}
+	for modelId, state := range ms.modelPortMap {
+		timeout := idleTimeout(conf, modelId)
+		if timeout <= 0 {
+			continue
+		}
+
+		state.Lock()
+		idle := isIdle(state, timeout, now)
+		state.Unlock()
+
+		if idle {
+			ret = append(ret, modelId)
+		}
+	}
+
+	return ret
+}
+
+/*
+claimIdle forgets the state of a model if it is still idle, so prompts
+using the model from now on don't share the state of the container being stopped.
+Use waits until finishStop is called for a claimed model.
+*/
+func (ms *ModelService) claimIdle(modelId string, timeout time.Duration, now time.Time) bool {
+	ms.modelStateMutex.Lock()
+	defer ms.modelStateMutex.Unlock()
+
+	state, ok := ms.modelPortMap[modelId]
+	if !ok {
+		return false
+	}
+
+	state.Lock()
+	defer state.Unlock()
+
+	if timeout <= 0 || !isIdle(state, timeout, now) {
+		return false
+	}
+
+	// also stops the checker of the model and frees its port
+	delete(ms.modelPortMap, modelId)
+	ms.stopping[modelId] = make(chan struct{})
+
+	return true
+}
+
+func (ms *ModelService) finishStop(modelId string) {
+	ms.modelStateMutex.Lock()
+	defer ms.modelStateMutex.Unlock()
+
+	if stopped, ok := ms.stopping[modelId]; ok {
+		close(stopped)
+		delete(ms.stopping, modelId)
+	}
+}
+
+func (ms *ModelService) waitForStop(modelId string) {
+	ms.modelStateMutex.Lock()
+	stopped, ok := ms.stopping[modelId]
+	ms.modelStateMutex.Unlock()
+
+	if ok {
+		<-stopped
+	}
+}
+
+// isIdle expects the state to be locked
+func isIdle(state *modeltypes.ModelState, timeout time.Duration, now time.Time) bool {
+	return state.Port != 0 &&
+		state.InUse == 0 &&
+		now.Sub(state.LastUsedAt) >= timeout
+}
+
+func idleTimeout(conf configtypes.ModelServiceConfig, modelId string) time.Duration {
+	minutes := conf.IdleTimeoutMinutes
+	if v, ok := conf.ModelIdleTimeoutMinutes[modelId]; ok {
+		minutes = v
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
@@ -0,0 +1,100 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package modelservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	configtypes "github.com/singulatron/singulatron/localtron/services/config/types"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+func TestIdleModels(t *testing.T) {
+	now := time.Now()
+	ms := &ModelService{
+		modelPortMap: map[string]*modeltypes.ModelState{
+			"idle":        {Port: 8001, LastUsedAt: now.Add(-11 * time.Minute)},
+			"recent":      {Port: 8002, LastUsedAt: now.Add(-time.Minute)},
+			"busy":        {Port: 8003, LastUsedAt: now.Add(-time.Hour), InUse: 1},
+			"pinned":      {Port: 8004, LastUsedAt: now.Add(-time.Hour)},
+			"not-started": {LastUsedAt: now.Add(-time.Hour)},
+		},
+	}
+	conf := configtypes.ModelServiceConfig{
+		IdleTimeoutMinutes: 10,
+		ModelIdleTimeoutMinutes: map[string]int{
+			"pinned": -1,
+		},
+	}
+
+	require.Equal(t, []string{"idle"}, ms.idleModels(conf, now))
+
+	conf.IdleTimeoutMinutes = 0
+	require.Equal(t, []string{}, ms.idleModels(conf, now), "no timeout keeps models running")
+}
+
+func TestUse(t *testing.T) {
+	ms := &ModelService{
+		modelPortMap: map[string]*modeltypes.ModelState{},
+	}
+
+	release := ms.Use("model")
+	state := ms.get("model")
+	require.Equal(t, 1, state.InUse)
+	require.False(t, state.LastUsedAt.IsZero())
+
+	release()
+	require.Equal(t, 0, state.InUse)
+}
+
+func TestClaimIdle(t *testing.T) {
+	now := time.Now()
+	ms := &ModelService{
+		modelPortMap: map[string]*modeltypes.ModelState{
+			"idle": {Port: 8001, LastUsedAt: now.Add(-time.Hour)},
+		},
+		stopping: map[string]chan struct{}{},
+	}
+	conf := configtypes.ModelServiceConfig{IdleTimeoutMinutes: 10}
+	require.Equal(t, []string{"idle"}, ms.idleModels(conf, now))
+
+	// a prompt picks the model up before it is stopped
+	release := ms.Use("idle")
+	require.False(t, ms.claimIdle("idle", 10*time.Minute, now))
+
+	release()
+	state := ms.get("idle")
+	require.True(t, ms.claimIdle("idle", 10*time.Minute, now.Add(time.Hour)))
+	require.NotSame(t, state, ms.get("idle"), "later prompts get a new state")
+
+	// prompts wait for the container to stop before using the model
+	used := make(chan struct{})
+	go func() {
+		ms.Use("idle")
+		close(used)
+	}()
+
+	select {
+	case <-used:
+		t.Fatal("model got used while its container is stopping")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	ms.finishStop("idle")
+	select {
+	case <-used:
+	case <-time.After(time.Second):
+		t.Fatal("model can't be used after its container stopped")
+	}
+}
@@ -0,0 +1,246 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package modelservice
+
+import (
+	"embed"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	"github.com/singulatron/singulatron/localtron/logger"
+
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+// bundledCatalogFiles are shipped with the binary and hold the default platforms and models
+//
+//go:embed catalog/*.yaml
+var bundledCatalogFiles embed.FS
+
+// catalogFolder is the folder in the config directory holding the catalog files of the user
+const catalogFolder = "catalog"
+
+const catalogReloadInterval = 10 * time.Second
+
+// catalogFile is a catalog file last read from the catalog folder
+type catalogFile struct {
+	modTime time.Time
+	size    int64
+	// catalog is the last valid content of the file, nil if it was never valid
+	catalog *modeltypes.Catalog
+}
+
+func readBundledCatalogs() ([]*modeltypes.Catalog, error) {
+	entries, err := fs.ReadDir(bundledCatalogFiles, catalogFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []*modeltypes.Catalog{}
+	for _, entry := range entries {
+		data, err := bundledCatalogFiles.ReadFile(path.Join(catalogFolder, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		catalog, err := parseCatalog(entry.Name(), data)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, catalog)
+	}
+
+	return ret, nil
+}
+
+/*
+loadCatalog merges the bundled catalogs with the catalog files of the
+catalog folder, in this order of precedence, and saves the result
+if any catalog file changed since the last load.
+*/
+func (ms *ModelService) loadCatalog() (bool, error) {
+	ms.catalogMutex.Lock()
+	defer ms.catalogMutex.Unlock()
+
+	changed, err := ms.readCatalogFolder()
+	if err != nil {
+		return false, err
+	}
+	if !changed && ms.catalogLoaded {
+		return false, nil
+	}
+
+	catalogs := append([]*modeltypes.Catalog{}, ms.bundledCatalogs...)
+
+	filePaths := []string{}
+	for filePath := range ms.catalogFiles {
+		filePaths = append(filePaths, filePath)
+	}
+	sort.Strings(filePaths)
+	for _, filePath := range filePaths {
+		if ms.catalogFiles[filePath].catalog != nil {
+			catalogs = append(catalogs, ms.catalogFiles[filePath].catalog)
+		}
+	}
+
+	catalog, skipped := mergeCatalogs(catalogs...)
+	for _, modelId := range skipped {
+		logger.Warn("Model of unknown platform left out of the catalog", slog.String("modelId", modelId))
+	}
+
+	err = ms.saveCatalog(catalog)
+	if err != nil {
+		return false, err
+	}
+	ms.catalogLoaded = true
+
+	return true, nil
+}
+
+/*
+readCatalogFolder reads the catalog files which were added or modified
+since the last read and forgets the removed ones.
+A file which fails to parse keeps its last valid content.
+*/
+func (ms *ModelService) readCatalogFolder() (bool, error) {
+	entries, err := os.ReadDir(ms.catalogDirectory)
+	if err != nil && !os.IsNotExist(err) {
+		return false, errors.Wrap(err, "error reading catalog folder")
+	}
+
+	changed := false
+	seen := map[string]bool{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isCatalogFile(entry.Name()) {
+			continue
+		}
+		filePath := path.Join(ms.catalogDirectory, entry.Name())
+		seen[filePath] = true
+
+		info, err := entry.Info()
+		if err != nil {
+			return false, errors.Wrap(err, "error reading catalog file")
+		}
+
+		previous, ok := ms.catalogFiles[filePath]
+		if ok && previous.modTime.Equal(info.ModTime()) && previous.size == info.Size() {
+			continue
+		}
+		changed = true
+
+		file := &catalogFile{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err == nil {
+			file.catalog, err = parseCatalog(entry.Name(), data)
+		}
+		if err != nil {
+			logger.Warn("Cannot load model catalog",
+				slog.String("path", filePath),
+				slog.String("error", err.Error()),
+			)
+			if ok {
+				file.catalog = previous.catalog
+			}
+		}
+
+		ms.catalogFiles[filePath] = file
+	}
+
+	for filePath := range ms.catalogFiles {
+		if !seen[filePath] {
+			delete(ms.catalogFiles, filePath)
+			changed = true
+		}
+	}
+
+	return changed, nil
+}
+
+// saveCatalog stores the platforms and models of the catalog and removes the ones no longer in it
+func (ms *ModelService) saveCatalog(catalog *modeltypes.Catalog) error {
+	err := ms.platformsStore.UpsertMany(catalog.Platforms)
+	if err != nil {
+		return err
+	}
+	err = ms.modelsStore.UpsertMany(catalog.Models)
+	if err != nil {
+		return err
+	}
+
+	platformIds := map[string]bool{}
+	for _, platform := range catalog.Platforms {
+		platformIds[platform.Id] = true
+	}
+	platforms, err := ms.platformsStore.Query(datastore.All()).Find()
+	if err != nil {
+		return err
+	}
+	stalePlatformIds := []string{}
+	for _, platform := range platforms {
+		if !platformIds[platform.Id] {
+			stalePlatformIds = append(stalePlatformIds, platform.Id)
+		}
+	}
+	if len(stalePlatformIds) > 0 {
+		err = ms.platformsStore.Query(datastore.Equal("id", stalePlatformIds)).Delete()
+		if err != nil {
+			return err
+		}
+	}
+
+	modelIds := map[string]bool{}
+	for _, model := range catalog.Models {
+		modelIds[model.Id] = true
+	}
+	models, err := ms.modelsStore.Query(datastore.All()).Find()
+	if err != nil {
+		return err
+	}
+	staleModelIds := []string{}
+	for _, model := range models {
+		if !modelIds[model.Id] {
+			staleModelIds = append(staleModelIds, model.Id)
+		}
+	}
+	if len(staleModelIds) > 0 {
+		return ms.modelsStore.Query(datastore.Equal("id", staleModelIds)).Delete()
+	}
+
+	return nil
+}
+
+// watchCatalog periodically reloads the catalog files of the catalog folder
+func (ms *ModelService) watchCatalog() {
+	for {
+		time.Sleep(catalogReloadInterval)
+
+		changed, err := ms.loadCatalog()
+		if err != nil {
+			logger.Warn("Error reloading model catalog", slog.String("error", err.Error()))
+			continue
+		}
+		if changed {
+			logger.Info("Reloaded model catalog")
+			ms.firehoseService.Publish(modeltypes.EventModelCatalogUpdate{})
+		}
+	}
+}
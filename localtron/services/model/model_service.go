@@ -11,13 +11,18 @@
 package modelservice
 
 import (
+	"os"
+	"path"
 	"sync"
 
+	"github.com/pkg/errors"
+
 	"github.com/singulatron/singulatron/localtron/datastore"
 
 	configservice "github.com/singulatron/singulatron/localtron/services/config"
 	dockerservice "github.com/singulatron/singulatron/localtron/services/docker"
 	downloadservice "github.com/singulatron/singulatron/localtron/services/download"
+	firehoseservice "github.com/singulatron/singulatron/localtron/services/firehose"
 	storefactoryservice "github.com/singulatron/singulatron/localtron/services/store_factory"
 	userservice "github.com/singulatron/singulatron/localtron/services/user"
 
@@ -26,7 +31,18 @@ import (
 
 type ModelService struct {
 	modelStateMutex sync.Mutex
-	modelPortMap    map[int]*modeltypes.ModelState
+	// modelPortMap is the state of started models by model id
+	modelPortMap map[string]*modeltypes.ModelState
+	portIsFree   func(port int) bool
+	// stopping are closed by model id once the idle container of the model is stopped
+	stopping map[string]chan struct{}
+
+	catalogMutex     sync.Mutex
+	catalogDirectory string
+	bundledCatalogs  []*modeltypes.Catalog
+	// catalogFiles are the files of the catalog folder by path
+	catalogFiles  map[string]*catalogFile
+	catalogLoaded bool
 
 	modelsStore    datastore.DataStore[*modeltypes.Model]
 	platformsStore datastore.DataStore[*modeltypes.Platform]
@@ -35,20 +51,29 @@ type ModelService struct {
 	downloadService *downloadservice.DownloadService
 	configService   *configservice.ConfigService
 	dockerService   *dockerservice.DockerService
+	firehoseService *firehoseservice.FirehoseService
 }
 
 func NewModelService(
 	ds *downloadservice.DownloadService,
 	userService *userservice.UserService,
 	cs *configservice.ConfigService,
-	dockerService *dockerservice.DockerService) (*ModelService, error) {
+	dockerService *dockerservice.DockerService,
+	firehoseService *firehoseservice.FirehoseService,
+) (*ModelService, error) {
 	srv := &ModelService{
 		userService:     userService,
 		downloadService: ds,
 		configService:   cs,
 		dockerService:   dockerService,
+		firehoseService: firehoseService,
+
+		modelPortMap: map[string]*modeltypes.ModelState{},
+		stopping:     map[string]chan struct{}{},
+		portIsFree:   defaultPortIsFree,
 
-		modelPortMap: map[int]*modeltypes.ModelState{},
+		catalogDirectory: path.Join(cs.ConfigDirectory, catalogFolder),
+		catalogFiles:     map[string]*catalogFile{},
 	}
 	modelStore, err := storefactoryservice.GetStore[*modeltypes.Model]("models")
 	if err != nil {
@@ -67,10 +92,21 @@ func NewModelService(
 		return nil, err
 	}
 
-	err = srv.bootstrapModels()
+	srv.bundledCatalogs, err = readBundledCatalogs()
 	if err != nil {
 		return nil, err
 	}
+	err = os.MkdirAll(srv.catalogDirectory, 0755)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating catalog folder")
+	}
+	_, err = srv.loadCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	go srv.unloadIdleModels()
+	go srv.watchCatalog()
 
 	return srv, nil
 }
@@ -0,0 +1,121 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package modelservice
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+// Host ports of model containers are allocated from this range
+const (
+	firstHostPort = 8001
+	lastHostPort  = 8100
+)
+
+var ErrNoFreePort = errors.New("no free port for model")
+
+/*
+allocatePort returns the host port of a model,
+allocating the lowest free one of the pool if the model has none.
+*/
+func (ms *ModelService) allocatePort(modelId string) (int, error) {
+	ms.modelStateMutex.Lock()
+	defer ms.modelStateMutex.Unlock()
+
+	state, ok := ms.modelPortMap[modelId]
+	if ok && state.Port != 0 {
+		return state.Port, nil
+	}
+
+	taken := map[int]bool{}
+	for _, v := range ms.modelPortMap {
+		taken[v.Port] = true
+	}
+
+	for port := firstHostPort; port <= lastHostPort; port++ {
+		if taken[port] || !ms.portIsFree(port) {
+			continue
+		}
+		if !ok {
+			state = &modeltypes.ModelState{}
+			ms.modelPortMap[modelId] = state
+		}
+		state.Port = port
+		return port, nil
+	}
+
+	return 0, ErrNoFreePort
+}
+
+// claimPort records the port a model is actually running on, eg. of a reused container
+func (ms *ModelService) claimPort(modelId string, port int) {
+	ms.modelStateMutex.Lock()
+	defer ms.modelStateMutex.Unlock()
+
+	state, ok := ms.modelPortMap[modelId]
+	if !ok {
+		state = &modeltypes.ModelState{}
+		ms.modelPortMap[modelId] = state
+	}
+	state.Port = port
+	if state.LastUsedAt.IsZero() {
+		// idle timeouts count from the start
+		state.LastUsedAt = time.Now()
+	}
+}
+
+// releasePort gives back the port of a model that failed to start
+func (ms *ModelService) releasePort(modelId string) {
+	ms.modelStateMutex.Lock()
+	defer ms.modelStateMutex.Unlock()
+
+	if state, ok := ms.modelPortMap[modelId]; ok {
+		state.Port = 0
+	}
+}
+
+// port returns the host port of a model, 0 if it has none
+func (ms *ModelService) port(modelId string) int {
+	ms.modelStateMutex.Lock()
+	defer ms.modelStateMutex.Unlock()
+
+	if state, ok := ms.modelPortMap[modelId]; ok {
+		return state.Port
+	}
+	return 0
+}
+
+/*
+defaultPortIsFree checks that nothing listens on a port of this machine.
+Containers of remote Docker hosts can't be checked this way,
+their ports are only known from the pool.
+*/
+func defaultPortIsFree(port int) bool {
+	listener, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+var invalidContainerNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// containerName returns the name of the container of a model, eg. singulatron-huggingface-TheBloke-...
+func containerName(modelId string) string {
+	return "singulatron-" + invalidContainerNameChars.ReplaceAllString(modelId, "-")
+}
@@ -0,0 +1,65 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package modelservice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+func TestAllocatePort(t *testing.T) {
+	busy := map[int]bool{firstHostPort + 1: true}
+	ms := &ModelService{
+		modelPortMap: map[string]*modeltypes.ModelState{},
+		portIsFree: func(port int) bool {
+			return !busy[port]
+		},
+	}
+
+	chat, err := ms.allocatePort("chat-model")
+	require.NoError(t, err)
+	require.Equal(t, firstHostPort, chat)
+
+	image, err := ms.allocatePort("image-model")
+	require.NoError(t, err)
+	require.Equal(t, firstHostPort+2, image, "busy ports are skipped")
+
+	again, err := ms.allocatePort("chat-model")
+	require.NoError(t, err)
+	require.Equal(t, chat, again, "a model keeps its port")
+
+	ms.releasePort("chat-model")
+	require.Equal(t, 0, ms.port("chat-model"))
+
+	other, err := ms.allocatePort("other-model")
+	require.NoError(t, err)
+	require.Equal(t, firstHostPort, other, "released ports are reused")
+
+	ms.claimPort("reused-model", 9000)
+	require.Equal(t, 9000, ms.port("reused-model"))
+
+	for port := firstHostPort; port <= lastHostPort; port++ {
+		busy[port] = true
+	}
+	_, err = ms.allocatePort("one-too-many")
+	require.True(t, errors.Is(err, ErrNoFreePort))
+}
+
+func TestContainerName(t *testing.T) {
+	require.Equal(t,
+		"singulatron-huggingface-TheBloke-mistral-7b-instruct-v0.2.Q3_K_S.gguf",
+		containerName("huggingface/TheBloke/mistral-7b-instruct-v0.2.Q3_K_S.gguf"),
+	)
+}
@@ -39,11 +39,3 @@ func (p *ModelService) registerPermissions() error {
 
 	return nil
 }
-
-func (p *ModelService) bootstrapModels() error {
-	err := p.platformsStore.UpsertMany(modeltypes.Platforms)
-	if err != nil {
-		return err
-	}
-	return p.modelsStore.UpsertMany(modeltypes.Models)
-}
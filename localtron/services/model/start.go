@@ -20,6 +20,7 @@ import (
 	"os"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,8 +33,6 @@ import (
 	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
 )
 
-const hostPortNum = 8001
-
 /*
 Starts the model which has the supplied modelId or the currently activated one of
 the modelId is empty.
@@ -84,12 +83,13 @@ func (ms *ModelService) Start(modelId string) error {
 	}
 
 	launchOptions := &dockerservice.LaunchOptions{
-		Name: platform.Id,
+		Name: containerName(model.Id),
 	}
 
 	image := platform.Architectures.Default.Image
 	port := platform.Architectures.Default.Port
-	launchOptions.Envs = platform.Architectures.Default.Envars
+	// copied as the envars of the model are appended
+	launchOptions.Envs = append([]string{}, platform.Architectures.Default.Envars...)
 	persistentPaths := platform.Architectures.Default.PersistentPaths
 
 	switch os.Getenv("SINGULATRON_GPU_PLATFORM") {
@@ -102,7 +102,7 @@ func (ms *ModelService) Start(modelId string) error {
 			port = platform.Architectures.Cuda.Port
 		}
 		if len(platform.Architectures.Cuda.Envars) > 0 {
-			launchOptions.Envs = platform.Architectures.Cuda.Envars
+			launchOptions.Envs = append([]string{}, platform.Architectures.Cuda.Envars...)
 		}
 		if len(platform.Architectures.Cuda.PersistentPaths) > 0 {
 			persistentPaths = platform.Architectures.Cuda.PersistentPaths
@@ -138,6 +138,12 @@ func (ms *ModelService) Start(modelId string) error {
 		launchOptions.HostBinds = append(launchOptions.HostBinds, fmt.Sprintf("%v:/assets/%v", assetPath, fileName))
 	}
 
+	if platform.ContextLengthEnvar != "" {
+		launchOptions.Envs = append(launchOptions.Envs,
+			fmt.Sprintf("%v=%v", platform.ContextLengthEnvar, model.GetContextLength()),
+		)
+	}
+
 	for _, persistentPath := range persistentPaths {
 		fold := singulatronHostFolder
 		if fold == "" {
@@ -154,13 +160,21 @@ func (ms *ModelService) Start(modelId string) error {
 	}
 	launchOptions.Hash = hash
 
-	launchInfo, err := ms.dockerService.LaunchContainer(image, port, hostPortNum, launchOptions)
+	hostPort, err := ms.allocatePort(model.Id)
 	if err != nil {
+		return err
+	}
+
+	launchInfo, err := ms.dockerService.LaunchContainer(image, port, hostPort, launchOptions)
+	if err != nil {
+		ms.releasePort(model.Id)
 		return errors.Wrap(err, "failed to launch container")
 	}
+	// a container already running the model keeps its port
+	ms.claimPort(model.Id, launchInfo.PortNumber)
 
 	if launchInfo.NewContainerStarted {
-		state := ms.get(launchInfo.PortNumber)
+		state := ms.get(model.Id)
 		if !state.HasCheckerRunning {
 			go ms.checkIfAnswers(model, platform, launchInfo.PortNumber, state)
 		}
@@ -191,16 +205,24 @@ func transformWinPaths(modelDir string) string {
 	return newModelDir
 }
 
-func (ms *ModelService) get(port int) *modeltypes.ModelState {
+func (ms *ModelService) get(modelId string) *modeltypes.ModelState {
 	ms.modelStateMutex.Lock()
 	defer ms.modelStateMutex.Unlock()
 
-	_, ok := ms.modelPortMap[port]
+	_, ok := ms.modelPortMap[modelId]
 	if !ok {
-		ms.modelPortMap[port] = &modeltypes.ModelState{}
+		ms.modelPortMap[modelId] = &modeltypes.ModelState{}
 	}
 
-	return ms.modelPortMap[port]
+	return ms.modelPortMap[modelId]
+}
+
+// isCurrent tells if a state is still the state of a model
+func (ms *ModelService) isCurrent(modelId string, state *modeltypes.ModelState) bool {
+	ms.modelStateMutex.Lock()
+	defer ms.modelStateMutex.Unlock()
+
+	return ms.modelPortMap[modelId] == state
 }
 
 func modelToHash(model *modeltypes.Model, platform *modeltypes.Platform) (string, error) {
@@ -214,6 +236,11 @@ func modelToHash(model *modeltypes.Model, platform *modeltypes.Platform) (string
 		return "", err
 	}
 
+	// containers started with an other context length are not reused
+	if platform.ContextLengthEnvar != "" {
+		bs1 = append(bs1, fmt.Sprintf(":%v", model.GetContextLength())...)
+	}
+
 	return generateStringHash(string(bs) + string(bs1)), nil
 }
 
@@ -248,6 +275,11 @@ func (ms *ModelService) checkIfAnswers(
 		}
 		first = false
 
+		if !ms.isCurrent(model.Id, state) {
+			// the model was stopped meanwhile
+			return
+		}
+
 		logger.Debug("Checking for answer started", slog.Int("port", port))
 
 		isModelRunning, err := ms.dockerService.HashIsRunning(hash)
@@ -291,6 +323,11 @@ func (ms *ModelService) checkIfAnswers(
 
 		logger.Debug("LLM pinged successfully", slog.Int("port", port))
 		state.SetAnswering(true)
+
+		ms.firehoseService.Publish(modeltypes.EventModelLoaded{
+			ModelId: model.Id,
+			Address: ms.address(port),
+		})
 		return
 	}
 }
@@ -310,7 +347,7 @@ func (ms *ModelService) printContainerLogs(modelId, hash string) {
 }
 
 func pingAddress(host string, port int) error {
-	address := fmt.Sprintf("%s:%d", host, port)
+	address := net.JoinHostPort(host, strconv.Itoa(port))
 	conn, err := net.DialTimeout("tcp", address, 2*time.Second)
 	if err != nil {
 		return err
@@ -21,14 +21,6 @@ import (
 )
 
 func (ms *ModelService) Status(modelId string) (*modeltypes.ModelStatus, error) {
-	dockerHost := ms.dockerService.GetDockerHost()
-	singulatronLLMHost := os.Getenv("SINGULATRON_LLM_HOST")
-	if singulatronLLMHost != "" {
-		dockerHost = singulatronLLMHost
-	}
-
-	modelAddress := fmt.Sprintf("%v:%v", dockerHost, hostPortNum)
-
 	if modelId == "" {
 		conf, err := ms.configService.GetConfig()
 		if err != nil {
@@ -50,6 +42,8 @@ func (ms *ModelService) Status(modelId string) (*modeltypes.ModelStatus, error)
 		return nil, errors.New("model not found")
 	}
 
+	modelAddress := ms.address(ms.port(model.Id))
+
 	for _, assetUrl := range model.Assets {
 
 		downl, exists := ms.downloadService.GetDownload(assetUrl)
@@ -81,16 +75,45 @@ func (ms *ModelService) Status(modelId string) (*modeltypes.ModelStatus, error)
 		isRunning = true
 	}
 
-	// @todo lock this
-	if v, ok := ms.modelPortMap[hostPortNum]; ok {
-		if !v.Answering {
+	if isRunning && modelAddress == "" {
+		// started before a restart of the daemon
+		port, found, err := ms.dockerService.HashHostPort(hash)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			ms.claimPort(model.Id, port)
+			modelAddress = ms.address(port)
+		} else {
 			isRunning = false
 		}
 	}
 
+	state := ms.get(model.Id)
+	state.Lock()
+	if state.HasCheckerRunning && !state.Answering {
+		isRunning = false
+	}
+	state.Unlock()
+
 	return &modeltypes.ModelStatus{
 		Running:     isRunning,
 		AssetsReady: true,
 		Address:     modelAddress,
 	}, nil
 }
+
+// address returns the address of a host port of the docker host, empty for no port
+func (ms *ModelService) address(port int) string {
+	if port == 0 {
+		return ""
+	}
+
+	dockerHost := ms.dockerService.GetDockerHost()
+	singulatronLLMHost := os.Getenv("SINGULATRON_LLM_HOST")
+	if singulatronLLMHost != "" {
+		dockerHost = singulatronLLMHost
+	}
+
+	return fmt.Sprintf("%v:%v", dockerHost, port)
+}
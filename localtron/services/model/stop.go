@@ -0,0 +1,83 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package modelservice
+
+import (
+	"github.com/pkg/errors"
+
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+/*
+Stops the container of the model which has the supplied modelId
+or of the currently activated one if the modelId is empty.
+*/
+func (ms *ModelService) Stop(modelId string) error {
+	if modelId == "" {
+		conf, err := ms.configService.GetConfig()
+		if err != nil {
+			return err
+		}
+		if conf.Model.CurrentModelId == "" {
+			return errors.New("no model id specified and no default model")
+		}
+		modelId = conf.Model.CurrentModelId
+	}
+
+	return ms.stop(modelId, modeltypes.UnloadReasonStopped)
+}
+
+func (ms *ModelService) stop(modelId string, reason modeltypes.UnloadReason) error {
+	err := ms.stopContainer(modelId, reason)
+	if err != nil {
+		return err
+	}
+
+	// also stops the checker of the model and frees its port
+	ms.modelStateMutex.Lock()
+	delete(ms.modelPortMap, modelId)
+	ms.modelStateMutex.Unlock()
+
+	return nil
+}
+
+func (ms *ModelService) stopContainer(modelId string, reason modeltypes.UnloadReason) error {
+	model, found, err := ms.GetModel(modelId)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("model not found")
+	}
+	platform, err := ms.GetPlatformByModelId(modelId)
+	if err != nil {
+		return err
+	}
+
+	hash, err := modelToHash(model, platform)
+	if err != nil {
+		return err
+	}
+
+	stopped, err := ms.dockerService.StopContainer(hash)
+	if err != nil {
+		return errors.Wrap(err, "failed to stop container")
+	}
+
+	if stopped {
+		ms.firehoseService.Publish(modeltypes.EventModelUnloaded{
+			ModelId: modelId,
+			Reason:  reason,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package modeltypes
+
+import (
+	"fmt"
+	"slices"
+)
+
+/*
+ImageParameters control how images are generated.
+Fields left empty fall back to the defaults of the platform.
+There is no negative prompt as the stable diffusion container has no input for it.
+*/
+type ImageParameters struct {
+	Width         int      `json:"width,omitempty"`
+	Height        int      `json:"height,omitempty"`
+	Steps         int      `json:"steps,omitempty"`
+	GuidanceScale *float64 `json:"guidanceScale,omitempty"`
+	// Seed makes generation reproducible, a random one is used when empty
+	Seed      *int   `json:"seed,omitempty"`
+	Scheduler string `json:"scheduler,omitempty"`
+	NumImages int    `json:"numImages,omitempty"`
+
+	// InputAssetId is the asset of the image to refine instead of starting from scratch
+	InputAssetId string `json:"inputAssetId,omitempty"`
+	// MaskAssetId is the asset of an image whose white pixels mark
+	// the part of the input image to paint over
+	MaskAssetId string `json:"maskAssetId,omitempty"`
+	// Strength is how much the input image is changed, from 0 to 1
+	Strength *float64 `json:"strength,omitempty"`
+}
+
+// ImageSupport describes the image generation parameters a platform accepts
+type ImageSupport struct {
+	ImageToImage bool     `json:"imageToImage"`
+	Inpainting   bool     `json:"inpainting"`
+	Schedulers   []string `json:"schedulers"`
+	MinSize      int      `json:"minSize"`
+	MaxSize      int      `json:"maxSize"`
+	// SizeStep is the number width and height must be a multiple of
+	SizeStep         int     `json:"sizeStep"`
+	MaxSteps         int     `json:"maxSteps"`
+	MaxGuidanceScale float64 `json:"maxGuidanceScale"`
+	MaxImages        int     `json:"maxImages"`
+
+	Defaults ImageParameters `json:"defaults"`
+}
+
+/*
+Resolve returns the parameters with the empty fields set to the defaults
+or an error if the parameters are not supported. The seed is left empty
+when neither the parameters nor the defaults have one.
+*/
+func (s ImageSupport) Resolve(params *ImageParameters) (ImageParameters, error) {
+	ret := s.Defaults
+	if params != nil {
+		if params.Width != 0 {
+			ret.Width = params.Width
+		}
+		if params.Height != 0 {
+			ret.Height = params.Height
+		}
+		if params.Steps != 0 {
+			ret.Steps = params.Steps
+		}
+		if params.GuidanceScale != nil {
+			ret.GuidanceScale = params.GuidanceScale
+		}
+		if params.Seed != nil {
+			ret.Seed = params.Seed
+		}
+		if params.Scheduler != "" {
+			ret.Scheduler = params.Scheduler
+		}
+		if params.NumImages != 0 {
+			ret.NumImages = params.NumImages
+		}
+		ret.InputAssetId = params.InputAssetId
+		ret.MaskAssetId = params.MaskAssetId
+		if params.Strength != nil {
+			ret.Strength = params.Strength
+		}
+	}
+
+	for _, size := range []int{ret.Width, ret.Height} {
+		if size < s.MinSize || size > s.MaxSize || size%s.SizeStep != 0 {
+			return ret, fmt.Errorf("width and height must be multiples of %d between %d and %d", s.SizeStep, s.MinSize, s.MaxSize)
+		}
+	}
+	if ret.Steps < 1 || ret.Steps > s.MaxSteps {
+		return ret, fmt.Errorf("steps must be between 1 and %d", s.MaxSteps)
+	}
+	if ret.GuidanceScale != nil && (*ret.GuidanceScale < 0 || *ret.GuidanceScale > s.MaxGuidanceScale) {
+		return ret, fmt.Errorf("guidanceScale must be between 0 and %v", s.MaxGuidanceScale)
+	}
+	if ret.Seed != nil && *ret.Seed < 0 {
+		return ret, fmt.Errorf("seed must not be negative")
+	}
+	if !slices.Contains(s.Schedulers, ret.Scheduler) {
+		return ret, fmt.Errorf("scheduler must be one of %v", s.Schedulers)
+	}
+	if ret.NumImages < 1 || ret.NumImages > s.MaxImages {
+		return ret, fmt.Errorf("numImages must be between 1 and %d", s.MaxImages)
+	}
+	if ret.InputAssetId != "" && !s.ImageToImage {
+		return ret, fmt.Errorf("input images are not supported")
+	}
+	if ret.MaskAssetId != "" && !s.Inpainting {
+		return ret, fmt.Errorf("masks are not supported")
+	}
+	if ret.MaskAssetId != "" && ret.InputAssetId == "" {
+		return ret, fmt.Errorf("a mask needs an input image")
+	}
+	if ret.Strength != nil && (*ret.Strength < 0 || *ret.Strength > 1) {
+		return ret, fmt.Errorf("strength must be between 0 and 1")
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,89 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package modeltypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageSupportResolve(t *testing.T) {
+	guidanceScale := 7.5
+	strength := 0.25
+	support := ImageSupport{
+		ImageToImage:     true,
+		Inpainting:       true,
+		Schedulers:       []string{"PNDM", "KLMS", "DDIM"},
+		MinSize:          256,
+		MaxSize:          1024,
+		SizeStep:         64,
+		MaxSteps:         150,
+		MaxGuidanceScale: 30,
+		MaxImages:        4,
+		Defaults: ImageParameters{
+			Width:         512,
+			Height:        512,
+			Steps:         50,
+			GuidanceScale: &guidanceScale,
+			Scheduler:     "PNDM",
+			NumImages:     1,
+			Strength:      &strength,
+		},
+	}
+
+	params, err := support.Resolve(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 512, params.Width)
+	assert.Equal(t, 50, params.Steps)
+	assert.Equal(t, 7.5, *params.GuidanceScale)
+	assert.Equal(t, "PNDM", params.Scheduler)
+	assert.Nil(t, params.Seed)
+
+	seed := 42
+	params, err = support.Resolve(&ImageParameters{
+		Width:     768,
+		Seed:      &seed,
+		Scheduler: "DDIM",
+		NumImages: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 768, params.Width)
+	assert.Equal(t, 512, params.Height)
+	assert.Equal(t, 42, *params.Seed)
+	assert.Equal(t, 2, params.NumImages)
+
+	params, err = support.Resolve(&ImageParameters{
+		InputAssetId: "input",
+		MaskAssetId:  "mask",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "input", params.InputAssetId)
+	assert.Equal(t, 0.25, *params.Strength)
+
+	guidance := 100.0
+	negativeSeed := -1
+	for _, invalid := range []*ImageParameters{
+		{Width: 500},
+		{Height: 2048},
+		{Steps: 1000},
+		{GuidanceScale: &guidance},
+		{Seed: &negativeSeed},
+		{Scheduler: "Unknown"},
+		{NumImages: 10},
+		{MaskAssetId: "mask"},
+		{InputAssetId: "input", Strength: &guidance},
+	} {
+		_, err = support.Resolve(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
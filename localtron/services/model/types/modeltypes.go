@@ -1,6 +1,10 @@
 package modeltypes
 
-import "sync"
+import (
+	"errors"
+	"sync"
+	"time"
+)
 
 /*
 Platform (~AI Platform) roughly represents an AI container + its settings.
@@ -10,6 +14,11 @@ type Platform struct {
 	Name          *string       `json:"name,omitempty"`
 	Version       *int          `json:"version,omitempty"`
 	Architectures Architectures `json:"architectures"`
+	// Image is set for platforms generating images
+	Image *ImageSupport `json:"image,omitempty"`
+	// ContextLengthEnvar is the envar the context length of
+	// the model is passed to the container in, see Model.GetContextLength
+	ContextLengthEnvar string `json:"contextLengthEnvar,omitempty"`
 }
 
 func (p Platform) GetId() string {
@@ -58,17 +67,156 @@ type Model struct {
 	MaxBits        int               `json:"max_bits"`
 	Bits           int               `json:"bits"`
 	Assets         map[string]string `json:"assets"`
+	/* RoleTemplates are used to assemble multi-turn prompts from
+	the history of a thread. Optional, see GetRoleTemplates. */
+	RoleTemplates *RoleTemplates `json:"role_templates,omitempty"`
+	/* ContextLength is the number of tokens the model can attend to,
+	prompt and answer included. See GetContextLength. */
+	ContextLength int `json:"context_length,omitempty"`
+	/* DefaultParameters are used for prompts which don't specify
+	their own sampling parameters. */
+	DefaultParameters *SamplingParameters `json:"default_parameters,omitempty"`
 }
 
 func (g Model) GetId() string {
 	return g.Id
 }
 
+/* DefaultContextLength is assumed for models which don't specify one. */
+const DefaultContextLength = 2048
+
+func (g Model) GetContextLength() int {
+	if g.ContextLength > 0 {
+		return g.ContextLength
+	}
+
+	return DefaultContextLength
+}
+
+/*
+GetRoleTemplates returns the role templates of the model.
+Models without explicit role templates fall back to
+using the PromptTemplate for user turns.
+*/
+func (g Model) GetRoleTemplates() RoleTemplates {
+	if g.RoleTemplates != nil {
+		return *g.RoleTemplates
+	}
+
+	userTemplate := g.PromptTemplate
+	if userTemplate == "" {
+		userTemplate = "{prompt}"
+	}
+
+	return RoleTemplates{
+		System:    "{prompt}\n",
+		User:      userTemplate,
+		Assistant: "{prompt}\n",
+	}
+}
+
+/*
+RoleTemplates describe how a message of a given role
+is rendered into the prompt sent to the model.
+The message content replaces the {prompt} placeholder, eg.
+
+	[INST] {prompt} [/INST]
+*/
+type RoleTemplates struct {
+	System    string `json:"system,omitempty"`
+	User      string `json:"user,omitempty"`
+	Assistant string `json:"assistant,omitempty"`
+}
+
+/*
+SamplingParameters control how a model picks the next token.
+Fields left empty fall back to the model defaults and
+then to the defaults of the inference engine.
+*/
+type SamplingParameters struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"topP,omitempty"`
+	TopK        *int     `json:"topK,omitempty"`
+	// Seed makes sampling deterministic
+	Seed *int `json:"seed,omitempty"`
+	// Stop sequences end the answer when generated
+	Stop []string `json:"stop,omitempty"`
+	// MaxTokens is the maximum length of the answer
+	MaxTokens *int `json:"maxTokens,omitempty"`
+}
+
+func (s SamplingParameters) Validate() error {
+	if s.Temperature != nil && *s.Temperature < 0 {
+		return errors.New("temperature must not be negative")
+	}
+	if s.TopP != nil && (*s.TopP <= 0 || *s.TopP > 1) {
+		return errors.New("topP must be greater than 0 and at most 1")
+	}
+	if s.TopK != nil && *s.TopK < 0 {
+		return errors.New("topK must not be negative")
+	}
+	if s.MaxTokens != nil && *s.MaxTokens <= 0 {
+		return errors.New("maxTokens must be positive")
+	}
+
+	return nil
+}
+
+/*
+Override returns a copy of the parameters with the
+fields set in the override taking precedence.
+*/
+func (s SamplingParameters) Override(override *SamplingParameters) SamplingParameters {
+	if override == nil {
+		return s
+	}
+
+	if override.Temperature != nil {
+		s.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		s.TopP = override.TopP
+	}
+	if override.TopK != nil {
+		s.TopK = override.TopK
+	}
+	if override.Seed != nil {
+		s.Seed = override.Seed
+	}
+	if override.Stop != nil {
+		s.Stop = override.Stop
+	}
+	if override.MaxTokens != nil {
+		s.MaxTokens = override.MaxTokens
+	}
+
+	return s
+}
+
+/*
+GetParameters returns the default parameters of the model
+overridden by the parameters of a prompt.
+*/
+func (g Model) GetParameters(override *SamplingParameters) SamplingParameters {
+	params := SamplingParameters{}
+	if g.DefaultParameters != nil {
+		params = *g.DefaultParameters
+	}
+
+	return params.Override(override)
+}
+
 /* Internal type for ModelService */
 type ModelState struct {
 	sync.Mutex
+	// Port is the host port of the container of the model
+	Port              int
 	Answering         bool
 	HasCheckerRunning bool
+	// LastUsedAt is when a prompt last used the model
+	LastUsedAt time.Time
+	// InUse is the number of prompts using the model right now
+	InUse int
 }
 
 // Setter methods for each field
@@ -89,12 +237,16 @@ type ModelStatus struct {
 	/* Running triggers onModelLaunch on the frontend.
 	Running is true when the model is both running and answering
 	- fully loaded. */
-	Running bool   `json:"running"`
+	Running bool `json:"running"`
+	// Address of the container of the model, eg. http://127.0.0.1:8002.
+	// Empty when the model has not been started.
 	Address string `json:"address"`
 }
 
 type StatusRequest struct {
-	Url string `json:"url"`
+	ModelId string `json:"modelId,omitempty"`
+	// Url is the model id, kept for older clients
+	Url string `json:"url,omitempty"`
 }
 
 type StatusResponse struct {
@@ -102,12 +254,19 @@ type StatusResponse struct {
 }
 
 type StartRequest struct {
-	ModelId string `json:"status,omitempty"`
+	ModelId string `json:"modelId,omitempty"`
 }
 
 type StartResponse struct {
 }
 
+type StopRequest struct {
+	ModelId string `json:"modelId,omitempty"`
+}
+
+type StopResponse struct {
+}
+
 type MakeDefaultRequest struct {
 	Url string `json:"url"`
 }
@@ -119,6 +278,15 @@ type GetModelsResponse struct {
 	Models []*Model `json:"models,omitempty"`
 }
 
+/*
+Catalog is the content of a model catalog file.
+Catalog files extend and override the bundled platforms and models by id.
+*/
+type Catalog struct {
+	Platforms []*Platform `json:"platforms,omitempty"`
+	Models    []*Model    `json:"models,omitempty"`
+}
+
 //
 // Events
 //
@@ -132,3 +300,44 @@ type EventModelReady struct {
 func (e EventModelReady) Name() string {
 	return EventModelReadyName
 }
+
+const EventModelLoadedName = "modelLoaded"
+
+// EventModelLoaded is published when a started model first answers
+type EventModelLoaded struct {
+	ModelId string `json:"modelId"`
+	Address string `json:"address"`
+}
+
+func (e EventModelLoaded) Name() string {
+	return EventModelLoadedName
+}
+
+type UnloadReason string
+
+const (
+	UnloadReasonStopped UnloadReason = "stopped"
+	UnloadReasonIdle    UnloadReason = "idle"
+)
+
+const EventModelUnloadedName = "modelUnloaded"
+
+// EventModelUnloaded is published when the container of a model is stopped
+type EventModelUnloaded struct {
+	ModelId string       `json:"modelId"`
+	Reason  UnloadReason `json:"reason"`
+}
+
+func (e EventModelUnloaded) Name() string {
+	return EventModelUnloadedName
+}
+
+const EventModelCatalogUpdateName = "modelCatalogUpdate"
+
+// EventModelCatalogUpdate is published when the catalog files are reloaded
+type EventModelCatalogUpdate struct {
+}
+
+func (e EventModelCatalogUpdate) Name() string {
+	return EventModelCatalogUpdateName
+}
@@ -0,0 +1,115 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package openaiservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+	openaitypes "github.com/singulatron/singulatron/localtron/services/openai/types"
+)
+
+var ErrInvalidMessages = errors.New("invalid messages")
+
+/*
+ChatCompletion answers the last message of a conversation.
+The earlier messages are rendered with the role templates of the model.
+*/
+func (s *OpenAIService) ChatCompletion(
+	ctx context.Context,
+	userId string,
+	req *openaitypes.ChatCompletionRequest,
+	onText func(text string),
+) (string, error) {
+	if len(req.Messages) == 0 ||
+		req.Messages[len(req.Messages)-1].Role != string(chattypes.MessageRoleUser) {
+		return "", fmt.Errorf("%w: the last message must be a user message", ErrInvalidMessages)
+	}
+
+	history := []*chattypes.Message{}
+	for _, message := range req.Messages[:len(req.Messages)-1] {
+		role := chattypes.MessageRole(message.Role)
+		switch role {
+		case chattypes.MessageRoleSystem, chattypes.MessageRoleUser, chattypes.MessageRoleAssistant:
+		default:
+			return "", fmt.Errorf("%w: unknown role '%v'", ErrInvalidMessages, message.Role)
+		}
+
+		history = append(history, &chattypes.Message{
+			Role:    role,
+			Content: message.Content,
+		})
+	}
+
+	schema, err := responseSchema(req.ResponseFormat)
+	if err != nil {
+		return "", err
+	}
+
+	return s.Generate(ctx, userId, Generation{
+		ModelId:        req.Model,
+		History:        history,
+		Prompt:         req.Messages[len(req.Messages)-1].Content,
+		ResponseSchema: schema,
+		Parameters: &modeltypes.SamplingParameters{
+			Temperature: req.Temperature,
+			TopP:        req.TopP,
+			MaxTokens:   req.MaxTokens,
+			Seed:        req.Seed,
+			Stop:        req.Stop,
+		},
+	}, onText)
+}
+
+// Completion continues a raw prompt without applying any templates
+func (s *OpenAIService) Completion(
+	ctx context.Context,
+	userId string,
+	req *openaitypes.CompletionRequest,
+	onText func(text string),
+) (string, error) {
+	return s.Generate(ctx, userId, Generation{
+		ModelId:  req.Model,
+		Prompt:   req.Prompt,
+		Template: "{prompt}",
+		Parameters: &modeltypes.SamplingParameters{
+			Temperature: req.Temperature,
+			TopP:        req.TopP,
+			MaxTokens:   req.MaxTokens,
+			Seed:        req.Seed,
+			Stop:        req.Stop,
+		},
+	}, onText)
+}
+
+// responseSchema converts a response format to the response schema of a prompt
+func responseSchema(format *openaitypes.ResponseFormat) (map[string]any, error) {
+	if format == nil {
+		return nil, nil
+	}
+
+	switch format.Type {
+	case "", openaitypes.ResponseFormatText:
+		return nil, nil
+	case openaitypes.ResponseFormatJSONObject:
+		return map[string]any{"type": "object"}, nil
+	case openaitypes.ResponseFormatJSONSchema:
+		if format.JSONSchema == nil || format.JSONSchema.Schema == nil {
+			return nil, fmt.Errorf("%w: json_schema is missing", ErrInvalidParameters)
+		}
+		return format.JSONSchema.Schema, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown response format '%v'", ErrInvalidParameters, format.Type)
+}
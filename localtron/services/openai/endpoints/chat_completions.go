@@ -0,0 +1,148 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package openaiendpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	openaiservice "github.com/singulatron/singulatron/localtron/services/openai"
+	openaitypes "github.com/singulatron/singulatron/localtron/services/openai/types"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func ChatCompletions(
+	w http.ResponseWriter,
+	r *http.Request,
+	userService *userservice.UserService,
+	openaiService *openaiservice.OpenAIService,
+) {
+	err := userService.IsAuthorized(prompttypes.PermissionPromptCreate.Id, r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	user, found, err := userService.GetUserFromRequest(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if !found {
+		writeError(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	req := &openaitypes.ChatCompletionRequest{}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		writeError(w, `invalid JSON`, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	id := "chatcmpl-" + uuid.New().String()
+	created := time.Now().Unix()
+
+	if !req.Stream {
+		var answer strings.Builder
+		finishReason, err := openaiService.ChatCompletion(r.Context(), user.Id, req, func(text string) {
+			answer.WriteString(text)
+		})
+		if err != nil {
+			writeError(w, err.Error(), errorStatus(err))
+			return
+		}
+
+		bs, _ := json.Marshal(openaitypes.ChatCompletionResponse{
+			Id:      id,
+			Object:  "chat.completion",
+			Created: created,
+			Model:   req.Model,
+			Choices: []openaitypes.ChatCompletionChoice{
+				{
+					Message: openaitypes.ChatMessage{
+						Role:    string(chattypes.MessageRoleAssistant),
+						Content: strings.TrimLeft(answer.String(), " "),
+					},
+					FinishReason: finishReason,
+				},
+			},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(bs)
+		return
+	}
+
+	chunk := func(delta openaitypes.ChatDelta, finishReason *string) openaitypes.ChatCompletionChunk {
+		return openaitypes.ChatCompletionChunk{
+			Id:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   req.Model,
+			Choices: []openaitypes.ChatCompletionChunkChoice{
+				{
+					Delta:        delta,
+					FinishReason: finishReason,
+				},
+			},
+		}
+	}
+
+	// the stream is only started once the answer arrives
+	// so early errors can be reported with a status code
+	started := false
+	first := true
+	finishReason, err := openaiService.ChatCompletion(r.Context(), user.Id, req, func(text string) {
+		if !started {
+			startStream(w)
+			writeJSONEvent(w, chunk(openaitypes.ChatDelta{
+				Role: string(chattypes.MessageRoleAssistant),
+			}, nil))
+			started = true
+		}
+		if first {
+			text = strings.TrimLeft(text, " ")
+			if text == "" {
+				return
+			}
+			first = false
+		}
+
+		writeJSONEvent(w, chunk(openaitypes.ChatDelta{Content: text}, nil))
+	})
+	if err != nil {
+		if !started {
+			writeError(w, err.Error(), errorStatus(err))
+			return
+		}
+		writeJSONEvent(w, openaitypes.ErrorResponse{
+			Error: openaitypes.ErrorDetails{
+				Message: err.Error(),
+				Type:    "server_error",
+			},
+		})
+		writeEvent(w, "[DONE]")
+		return
+	}
+
+	if !started {
+		startStream(w)
+	}
+	writeJSONEvent(w, chunk(openaitypes.ChatDelta{}, &finishReason))
+	writeEvent(w, "[DONE]")
+}
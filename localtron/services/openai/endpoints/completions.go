@@ -0,0 +1,121 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package openaiendpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	openaiservice "github.com/singulatron/singulatron/localtron/services/openai"
+	openaitypes "github.com/singulatron/singulatron/localtron/services/openai/types"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func Completions(
+	w http.ResponseWriter,
+	r *http.Request,
+	userService *userservice.UserService,
+	openaiService *openaiservice.OpenAIService,
+) {
+	err := userService.IsAuthorized(prompttypes.PermissionPromptCreate.Id, r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	user, found, err := userService.GetUserFromRequest(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if !found {
+		writeError(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	req := &openaitypes.CompletionRequest{}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		writeError(w, `invalid JSON`, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	id := "cmpl-" + uuid.New().String()
+	created := time.Now().Unix()
+
+	response := func(text string, finishReason *string) openaitypes.CompletionResponse {
+		return openaitypes.CompletionResponse{
+			Id:      id,
+			Object:  "text_completion",
+			Created: created,
+			Model:   req.Model,
+			Choices: []openaitypes.CompletionChoice{
+				{
+					Text:         text,
+					FinishReason: finishReason,
+				},
+			},
+		}
+	}
+
+	if !req.Stream {
+		var answer strings.Builder
+		finishReason, err := openaiService.Completion(r.Context(), user.Id, req, func(text string) {
+			answer.WriteString(text)
+		})
+		if err != nil {
+			writeError(w, err.Error(), errorStatus(err))
+			return
+		}
+
+		bs, _ := json.Marshal(response(answer.String(), &finishReason))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(bs)
+		return
+	}
+
+	// the stream is only started once the answer arrives
+	// so early errors can be reported with a status code
+	started := false
+	finishReason, err := openaiService.Completion(r.Context(), user.Id, req, func(text string) {
+		if !started {
+			startStream(w)
+			started = true
+		}
+		writeJSONEvent(w, response(text, nil))
+	})
+	if err != nil {
+		if !started {
+			writeError(w, err.Error(), errorStatus(err))
+			return
+		}
+		writeJSONEvent(w, openaitypes.ErrorResponse{
+			Error: openaitypes.ErrorDetails{
+				Message: err.Error(),
+				Type:    "server_error",
+			},
+		})
+		writeEvent(w, "[DONE]")
+		return
+	}
+
+	if !started {
+		startStream(w)
+	}
+	writeJSONEvent(w, response("", &finishReason))
+	writeEvent(w, "[DONE]")
+}
@@ -0,0 +1,47 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package openaiendpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+	openaiservice "github.com/singulatron/singulatron/localtron/services/openai"
+	openaitypes "github.com/singulatron/singulatron/localtron/services/openai/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func Models(
+	w http.ResponseWriter,
+	r *http.Request,
+	userService *userservice.UserService,
+	openaiService *openaiservice.OpenAIService,
+) {
+	err := userService.IsAuthorized(modeltypes.PermissionModelView.Id, r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	models, err := openaiService.ListModels()
+	if err != nil {
+		writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bs, _ := json.Marshal(openaitypes.ListModelsResponse{
+		Object: "list",
+		Data:   models,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(bs)
+}
@@ -0,0 +1,90 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package openaiendpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/singulatron/singulatron/localtron/logger"
+
+	openaiservice "github.com/singulatron/singulatron/localtron/services/openai"
+	openaitypes "github.com/singulatron/singulatron/localtron/services/openai/types"
+	promptservice "github.com/singulatron/singulatron/localtron/services/prompt"
+)
+
+// OpenAI clients expect errors in JSON
+func writeError(w http.ResponseWriter, message string, status int) {
+	errorType := "server_error"
+	switch status {
+	case http.StatusUnauthorized:
+		errorType = "authentication_error"
+	case http.StatusBadRequest, http.StatusNotFound:
+		errorType = "invalid_request_error"
+	case http.StatusTooManyRequests:
+		errorType = "rate_limit_error"
+	}
+
+	bs, _ := json.Marshal(openaitypes.ErrorResponse{
+		Error: openaitypes.ErrorDetails{
+			Message: message,
+			Type:    errorType,
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(bs)
+}
+
+func errorStatus(err error) int {
+	quotaErr := &promptservice.QuotaExceededError{}
+
+	switch {
+	case errors.As(err, &quotaErr):
+		return http.StatusTooManyRequests
+	case errors.Is(err, openaiservice.ErrModelNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, openaiservice.ErrUnsupportedModel),
+		errors.Is(err, openaiservice.ErrInvalidMessages),
+		errors.Is(err, openaiservice.ErrInvalidParameters):
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
+
+func startStream(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeEvent(w http.ResponseWriter, data string) {
+	_, err := w.Write([]byte("data: " + data + "\n\n"))
+	if err != nil {
+		logger.Warn("Failed to write streaming response")
+		return
+	}
+
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func writeJSONEvent(w http.ResponseWriter, v any) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	writeEvent(w, string(bs))
+}
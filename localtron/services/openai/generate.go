@@ -0,0 +1,372 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package openaiservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/clients/llm"
+	"github.com/singulatron/singulatron/localtron/logger"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	firehosetypes "github.com/singulatron/singulatron/localtron/services/firehose/types"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+	promptservice "github.com/singulatron/singulatron/localtron/services/prompt"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+var (
+	ErrModelNotFound     = errors.New("model not found")
+	ErrUnsupportedModel  = errors.New("model does not generate text")
+	ErrPromptCanceled    = errors.New("prompt got canceled")
+	ErrInvalidParameters = errors.New("invalid parameters")
+)
+
+// the stream manager drops responses for subscribers which can't keep up
+const streamBufferSize = 1024
+
+// threadCleanupTimeout is how long a request thread is kept
+// at most waiting for its prompt to finish
+const threadCleanupTimeout = 10 * time.Minute
+
+type Generation struct {
+	ModelId string
+	// History are the messages of the conversation before the prompt
+	History []*chattypes.Message
+	Prompt  string
+	// Template overrides the user role template of the model.
+	// "{prompt}" sends the prompt as it is.
+	Template   string
+	Parameters *modeltypes.SamplingParameters
+	// ResponseSchema makes the answer JSON matching the schema.
+	// The answer is only passed to onText once it is validated.
+	ResponseSchema map[string]any
+}
+
+/*
+Generate runs a prompt in a thread created for the request only,
+onText is called with every piece of the answer as it is streamed.
+Returns the finish reason of the model.
+Canceling the context cancels the prompt.
+Failed prompts are retried, except once a part of the answer is streamed.
+The thread is deleted only once the prompt is done with it,
+which can be after Generate returns.
+*/
+func (s *OpenAIService) Generate(
+	ctx context.Context,
+	userId string,
+	gen Generation,
+	onText func(text string),
+) (string, error) {
+	if gen.Parameters != nil {
+		err := gen.Parameters.Validate()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidParameters, err)
+		}
+	}
+
+	if gen.ResponseSchema != nil {
+		_, err := llm.JSONSchemaToGrammar(gen.ResponseSchema)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidParameters, err)
+		}
+	}
+
+	modelId, err := s.resolveModel(gen.ModelId)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	threadId := uuid.New().String()
+	promptId := uuid.New().String()
+
+	finished := make(chan prompttypes.EventPromptProcessingFinished, 16)
+	subscriptionId := s.firehoseService.Subscribe(func(events []firehosetypes.Event) {
+		for _, event := range events {
+			var finish prompttypes.EventPromptProcessingFinished
+			switch ev := event.(type) {
+			case prompttypes.EventPromptProcessingFinished:
+				finish = ev
+			case prompttypes.EventPromptCanceled:
+				// prompts canceled in the queue are not processed
+				finish = prompttypes.EventPromptProcessingFinished{
+					PromptId: ev.PromptId,
+					Status:   prompttypes.PromptStatusCanceled,
+				}
+			default:
+				continue
+			}
+			if finish.PromptId != promptId {
+				continue
+			}
+
+			select {
+			case finished <- finish:
+			default:
+			}
+		}
+	})
+
+	// done tells if the prompt is not queued or reached a final status
+	done := true
+	defer func() {
+		if done {
+			s.firehoseService.Unsubscribe(subscriptionId)
+			s.deleteThread(threadId)
+			return
+		}
+		// the processor might still be saving to the thread
+		go s.deleteThreadWhenFinished(threadId, subscriptionId, finished)
+	}()
+
+	_, err = s.chatService.AddThread(&chattypes.Thread{
+		Id:        threadId,
+		UserIds:   []string{userId},
+		Title:     "API request",
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "error creating thread")
+	}
+
+	for i, message := range gen.History {
+		message.ThreadId = threadId
+		message.CreatedAt = now.Add(time.Duration(i) * time.Millisecond)
+		if message.GetRole() == chattypes.MessageRoleAssistant {
+			// answers are saved HTML escaped, see llmResponseToText
+			message.Content = html.EscapeString(message.Content)
+		}
+		if message.GetRole() == chattypes.MessageRoleUser {
+			message.UserId = userId
+		}
+
+		err = s.chatService.AddMessage(message)
+		if err != nil {
+			return "", errors.Wrap(err, "error saving message")
+		}
+	}
+
+	subscriber := make(promptservice.SubscriberChan, streamBufferSize)
+	// the thread is new so nothing was missed
+	s.promptService.StreamManager.Subscribe(threadId, subscriber, 0)
+	defer s.promptService.StreamManager.Unsubscribe(threadId, subscriber)
+
+	err = s.promptService.AddPrompt(&prompttypes.Prompt{
+		Id:             promptId,
+		ThreadId:       threadId,
+		UserId:         userId,
+		Prompt:         gen.Prompt,
+		Template:       gen.Template,
+		ModelId:        modelId,
+		Parameters:     gen.Parameters,
+		ResponseSchema: gen.ResponseSchema,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "error adding prompt")
+	}
+	done = false
+
+	// streamed tells if a part of the answer was already passed to onText
+	streamed := false
+	handle := func(event *promptservice.StreamEvent) string {
+		resp := event.Response
+		if len(resp.Choices) == 0 {
+			return ""
+		}
+		if resp.Choices[0].Text != "" {
+			streamed = true
+		}
+		onText(resp.Choices[0].Text)
+		return resp.Choices[0].FinishReason
+	}
+
+	for {
+		select {
+		case resp, ok := <-subscriber:
+			if !ok {
+				return "", errors.New("stream closed")
+			}
+			// answers failing validation are streamed too before being retried
+			if gen.ResponseSchema != nil {
+				continue
+			}
+			if finishReason := handle(resp); finishReason != "" {
+				return finishReason, nil
+			}
+
+		case finish := <-finished:
+			done = isFinal(finish.Status)
+			switch finish.Status {
+			case prompttypes.PromptStatusCompleted:
+			case prompttypes.PromptStatusAbandoned:
+				return "", errors.New(finish.Error)
+			case prompttypes.PromptStatusCanceled:
+				return "", ErrPromptCanceled
+			default:
+				// errored prompts are retried, unless a part of the answer
+				// was already streamed as the retry would send it again
+				if !streamed {
+					continue
+				}
+				s.cancel(userId, promptId)
+				return "", errors.New(finish.Error)
+			}
+
+			if gen.ResponseSchema != nil {
+				return s.structuredAnswer(threadId, onText)
+			}
+
+			// the answer is broadcasted before the prompt finishes
+			// so it is waiting in the buffer
+			for {
+				select {
+				case resp := <-subscriber:
+					if finishReason := handle(resp); finishReason != "" {
+						return finishReason, nil
+					}
+				default:
+					return "stop", nil
+				}
+			}
+
+		case <-ctx.Done():
+			s.cancel(userId, promptId)
+			return "", ctx.Err()
+		}
+	}
+}
+
+func isFinal(status prompttypes.PromptStatus) bool {
+	return status == prompttypes.PromptStatusCompleted ||
+		status == prompttypes.PromptStatusAbandoned ||
+		status == prompttypes.PromptStatusCanceled
+}
+
+// deleteThreadWhenFinished deletes a request thread once its prompt reached a final status
+func (s *OpenAIService) deleteThreadWhenFinished(
+	threadId string,
+	subscriptionId int,
+	finished chan prompttypes.EventPromptProcessingFinished,
+) {
+	defer s.firehoseService.Unsubscribe(subscriptionId)
+
+	timeout := time.After(threadCleanupTimeout)
+	for {
+		select {
+		case finish := <-finished:
+			if !isFinal(finish.Status) {
+				continue
+			}
+		case <-timeout:
+			logger.Warn("Prompt of request thread did not finish in time",
+				slog.String("threadId", threadId),
+			)
+		}
+		break
+	}
+
+	s.deleteThread(threadId)
+}
+
+func (s *OpenAIService) cancel(userId, promptId string) {
+	err := s.promptService.Cancel(userId, promptId)
+	if err != nil {
+		logger.Error("Error canceling prompt",
+			slog.String("promptId", promptId),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// structuredAnswer passes the validated answer saved to the thread to onText
+func (s *OpenAIService) structuredAnswer(threadId string, onText func(text string)) (string, error) {
+	messages, _, err := s.chatService.GetMessages(threadId)
+	if err != nil {
+		return "", errors.Wrap(err, "error getting answer")
+	}
+
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Structured == nil {
+			continue
+		}
+
+		bs, err := json.Marshal(messages[i].Structured)
+		if err != nil {
+			return "", err
+		}
+		onText(string(bs))
+		return "stop", nil
+	}
+
+	return "", errors.New("no structured answer found")
+}
+
+// resolveModel falls back to the default model and
+// checks that the model can generate text
+func (s *OpenAIService) resolveModel(modelId string) (string, error) {
+	if modelId == "" {
+		conf, err := s.configService.GetConfig()
+		if err != nil {
+			return "", err
+		}
+		modelId = conf.Model.CurrentModelId
+	}
+
+	model, found, err := s.modelService.GetModel(modelId)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", ErrModelNotFound
+	}
+	if model.PlatformId != modeltypes.PlatformIdLlamaCpp {
+		return "", ErrUnsupportedModel
+	}
+
+	return modelId, nil
+}
+
+func (s *OpenAIService) deleteThread(threadId string) {
+	messages, _, err := s.chatService.GetMessages(threadId)
+	if err != nil {
+		logger.Error("Error getting messages of request thread",
+			slog.String("threadId", threadId),
+			slog.String("error", err.Error()),
+		)
+	}
+	for _, message := range messages {
+		err = s.chatService.DeleteMessage(message.Id)
+		if err != nil {
+			logger.Error("Error deleting message of request thread",
+				slog.String("messageId", message.Id),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	err = s.chatService.DeleteThread(threadId)
+	if err != nil {
+		logger.Error("Error deleting request thread",
+			slog.String("threadId", threadId),
+			slog.String("error", err.Error()),
+		)
+	}
+}
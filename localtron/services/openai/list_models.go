@@ -0,0 +1,39 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package openaiservice
+
+import (
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+	openaitypes "github.com/singulatron/singulatron/localtron/services/openai/types"
+)
+
+// ListModels lists the text generation models
+func (s *OpenAIService) ListModels() ([]*openaitypes.Model, error) {
+	models, err := s.modelService.GetModels()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []*openaitypes.Model{}
+	for _, model := range models {
+		if model.PlatformId != modeltypes.PlatformIdLlamaCpp {
+			continue
+		}
+
+		ret = append(ret, &openaitypes.Model{
+			Id:      model.Id,
+			Object:  "model",
+			OwnedBy: "singulatron",
+		})
+	}
+
+	return ret, nil
+}
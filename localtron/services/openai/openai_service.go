@@ -0,0 +1,54 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package openaiservice
+
+import (
+	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
+	configservice "github.com/singulatron/singulatron/localtron/services/config"
+	firehoseservice "github.com/singulatron/singulatron/localtron/services/firehose"
+	modelservice "github.com/singulatron/singulatron/localtron/services/model"
+	promptservice "github.com/singulatron/singulatron/localtron/services/prompt"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+/*
+OpenAIService exposes the models of Singulatron through
+an OpenAI compatible API. Requests are turned into prompts
+so they go through the same queue as the prompts of the UI.
+*/
+type OpenAIService struct {
+	configService   *configservice.ConfigService
+	userService     *userservice.UserService
+	modelService    *modelservice.ModelService
+	chatService     *chatservice.ChatService
+	promptService   *promptservice.PromptService
+	firehoseService *firehoseservice.FirehoseService
+}
+
+func NewOpenAIService(
+	cs *configservice.ConfigService,
+	userService *userservice.UserService,
+	modelService *modelservice.ModelService,
+	chatService *chatservice.ChatService,
+	promptService *promptservice.PromptService,
+	firehoseService *firehoseservice.FirehoseService,
+) (*OpenAIService, error) {
+	service := &OpenAIService{
+		configService:   cs,
+		userService:     userService,
+		modelService:    modelService,
+		chatService:     chatService,
+		promptService:   promptService,
+		firehoseService: firehoseService,
+	}
+
+	return service, nil
+}
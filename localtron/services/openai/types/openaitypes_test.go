@@ -0,0 +1,39 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package openaitypes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopSequencesUnmarshal(t *testing.T) {
+	req := ChatCompletionRequest{}
+	err := json.Unmarshal([]byte(`{"model":"m","stop":"\n"}`), &req)
+	require.NoError(t, err)
+	assert.Equal(t, StopSequences{"\n"}, req.Stop)
+
+	req = ChatCompletionRequest{}
+	err = json.Unmarshal([]byte(`{"model":"m","stop":["User:","###"]}`), &req)
+	require.NoError(t, err)
+	assert.Equal(t, StopSequences{"User:", "###"}, req.Stop)
+
+	req = ChatCompletionRequest{}
+	err = json.Unmarshal([]byte(`{"model":"m"}`), &req)
+	require.NoError(t, err)
+	assert.Nil(t, req.Stop)
+
+	err = json.Unmarshal([]byte(`{"model":"m","stop":1}`), &req)
+	assert.Error(t, err)
+}
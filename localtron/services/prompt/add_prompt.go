@@ -11,6 +11,7 @@
 package promptservice
 
 import (
+	"fmt"
 	"log/slog"
 	"time"
 
@@ -23,13 +24,31 @@ import (
 
 const maxThreadTitle = 100
 
+var ErrInvalidPrompt = errors.New("invalid prompt")
+
+/*
+AddPrompt queues a prompt.
+Returns a QuotaExceededError if the user of the prompt ran out of a quota
+and ErrInvalidPrompt if the model can't answer the prompt.
+*/
 func (p *PromptService) AddPrompt(prompt *prompttypes.Prompt) error {
+	if prompt.ResponseSchema != nil && len(prompt.ToolIds) > 0 {
+		return fmt.Errorf("%w: tools can't be used with a response schema", ErrInvalidPrompt)
+	}
+
+	if prompt.ImageParameters != nil {
+		err := p.validateImageParameters(prompt)
+		if err != nil {
+			return err
+		}
+	}
+
 	prompt.Status = prompttypes.PromptStatusScheduled
 	now := timeNow()
 	prompt.CreatedAt = now
 	prompt.UpdatedAt = now
 
-	err := p.promptsStore.Create(prompt)
+	err := p.createPrompt(prompt)
 	if err != nil {
 		return err
 	}
@@ -83,6 +102,18 @@ func (p *PromptService) AddPrompt(prompt *prompttypes.Prompt) error {
 	return nil
 }
 
+func (p *PromptService) createPrompt(prompt *prompttypes.Prompt) error {
+	p.quotaMutex.Lock()
+	defer p.quotaMutex.Unlock()
+
+	err := p.checkQuota(prompt.UserId)
+	if err != nil {
+		return err
+	}
+
+	return p.promptsStore.Create(prompt)
+}
+
 func (p *PromptService) triggerPromptProcessing() {
 	select {
 	case p.trigger <- true:
@@ -91,3 +122,40 @@ func (p *PromptService) triggerPromptProcessing() {
 		logger.Debug("Prompt trigger signal skipped, already pending")
 	}
 }
+
+// validateImageParameters checks that the model of the prompt supports its image parameters
+func (p *PromptService) validateImageParameters(prompt *prompttypes.Prompt) error {
+	modelId := prompt.ModelId
+	if modelId == "" {
+		conf, err := p.configService.GetConfig()
+		if err != nil {
+			return err
+		}
+		modelId = conf.Model.CurrentModelId
+	}
+
+	platform, err := p.modelService.GetPlatformByModelId(modelId)
+	if err != nil {
+		return errors.Wrap(err, "error getting platform")
+	}
+	if platform.Image == nil {
+		return fmt.Errorf("%w: model '%v' does not generate images", ErrInvalidPrompt, modelId)
+	}
+
+	params, err := platform.Image.Resolve(prompt.ImageParameters)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPrompt, err)
+	}
+
+	for _, assetId := range []string{params.InputAssetId, params.MaskAssetId} {
+		_, err = p.imageDataURL(assetId)
+		if errors.Is(err, ErrImageAssetNotFound) {
+			return fmt.Errorf("%w: %v", ErrInvalidPrompt, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
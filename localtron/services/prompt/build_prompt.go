@@ -0,0 +1,297 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+	tooltypes "github.com/singulatron/singulatron/localtron/services/tool/types"
+)
+
+const defaultHistoryMessageLimit = 20
+
+// turn is a single message of a conversation in the form
+// it gets rendered into the prompt of a model.
+type turn struct {
+	Role    chattypes.MessageRole
+	Content string
+	// MessageId is the id of the message the turn was made from
+	MessageId string
+	// SummaryOf is the ids of the messages a summary turn stands in for
+	SummaryOf []string
+}
+
+/*
+buildFullPrompt assembles the earlier messages of the thread
+of the prompt, the prompt itself and the tool steps of answering it
+into a multi-turn prompt using the role templates of the model.
+The system prompt of the thread template, the document passages
+retrieved for the prompt and the instructions for calling the tools come first.
+The history is cut to fit the context window of the model, see contextBuilder.
+The answer is given MaxTokens of room if set, defaultCompletionReserve otherwise.
+*/
+func (p *PromptService) buildFullPrompt(
+	ctx context.Context,
+	address string,
+	model *modeltypes.Model,
+	params modeltypes.SamplingParameters,
+	currentPrompt *prompttypes.Prompt,
+	tools []*tooltypes.Tool,
+	citations []*chattypes.Citation,
+) (string, error) {
+	conf, err := p.configService.GetConfig()
+	if err != nil {
+		return "", err
+	}
+
+	limit := conf.Prompt.HistoryMessageLimit
+	if limit == 0 {
+		limit = defaultHistoryMessageLimit
+	}
+
+	messages, _, err := p.appService.GetMessages(currentPrompt.ThreadId)
+	if err != nil {
+		return "", errors.Wrap(err, "error getting thread messages")
+	}
+
+	history := []turn{}
+	if limit > 0 {
+		history = historyTurns(messages, currentPrompt.Id, limit)
+	}
+
+	strategy := currentPrompt.ContextStrategy
+	if strategy == "" {
+		strategy = prompttypes.ContextStrategy(conf.Prompt.ContextStrategy)
+	}
+
+	reserve := defaultCompletionReserve
+	if params.MaxTokens != nil {
+		reserve = *params.MaxTokens
+	}
+
+	tpl, err := p.threadTemplate(currentPrompt.ThreadId)
+	if err != nil {
+		return "", errors.Wrap(err, "error rendering thread template")
+	}
+
+	pinned := []turn{}
+	if tpl != nil && strings.TrimSpace(tpl.SystemPrompt) != "" {
+		pinned = append(pinned, turn{
+			Role:    chattypes.MessageRoleSystem,
+			Content: tpl.SystemPrompt,
+		})
+	}
+
+	if len(citations) > 0 {
+		pinned = append(pinned, turn{
+			Role:    chattypes.MessageRoleSystem,
+			Content: citationInstructions(citations),
+		})
+	}
+
+	if len(tools) > 0 {
+		pinned = append(pinned, turn{
+			Role:    chattypes.MessageRoleSystem,
+			Content: toolInstructions(tools),
+		})
+	}
+
+	templates := roleTemplates(model, tpl, currentPrompt)
+	tokenizer := newLlmTokenizer(address, model.Id, p.tokenCounts)
+	builder := &contextBuilder{
+		tokenizer:     tokenizer,
+		templates:     templates,
+		contextLength: model.GetContextLength(),
+		reserve:       reserve,
+		strategy:      strategy,
+		keepLast:      conf.Prompt.KeepLastMessages,
+		summarize: func(turns []turn) (string, error) {
+			return p.summarize(ctx, address, tokenizer, model, turns)
+		},
+		pinned: pinned,
+	}
+
+	built, err := builder.build(history, promptTurns(messages, currentPrompt))
+	if err != nil {
+		return "", err
+	}
+
+	if built.Summary != nil {
+		err = p.saveSummary(currentPrompt.ThreadId, messages, built)
+		if err != nil {
+			return "", errors.Wrap(err, "error saving summary")
+		}
+	}
+
+	return renderTurns(built.Turns, templates), nil
+}
+
+/*
+historyTurns converts the last `limit` messages of a thread before
+the message of the prompt being processed to turns, see promptTurns
+for the message of the prompt and the ones after it.
+Messages are expected to be ordered by creation time.
+*/
+func historyTurns(messages []*chattypes.Message, promptId string, limit int) []turn {
+	summarized := map[string]bool{}
+	for _, message := range messages {
+		for _, id := range message.SummaryOf {
+			summarized[id] = true
+		}
+	}
+
+	history := []*chattypes.Message{}
+	afterPrompt := false
+	for _, message := range messages {
+		if message.Id == promptId {
+			afterPrompt = true
+			continue
+		}
+		// summaries are saved late but stand in for earlier messages
+		if afterPrompt && len(message.SummaryOf) == 0 {
+			continue
+		}
+		if summarized[message.Id] || isEmptyMessage(message) {
+			continue
+		}
+		history = append(history, message)
+	}
+
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+
+	turns := []turn{}
+	for _, message := range history {
+		turns = append(turns, messageTurn(message))
+	}
+
+	return turns
+}
+
+/*
+promptTurns are the turns of the prompt being processed: its message
+followed by the tool calls and results of the earlier steps of answering it,
+in the order they were saved, see processLlamaCppSteps.
+The prompt is added on its own if its message is not in the thread.
+*/
+func promptTurns(messages []*chattypes.Message, currentPrompt *prompttypes.Prompt) []turn {
+	turns := []turn{}
+	found := false
+	for _, message := range messages {
+		if message.Id == currentPrompt.Id {
+			found = true
+		}
+		if !found || len(message.SummaryOf) > 0 || isEmptyMessage(message) {
+			continue
+		}
+		turns = append(turns, messageTurn(message))
+	}
+
+	if !found {
+		turns = append(turns, turn{
+			Role:      chattypes.MessageRoleUser,
+			Content:   currentPrompt.Prompt,
+			MessageId: currentPrompt.Id,
+		})
+	}
+
+	return turns
+}
+
+// tool calls are sent even without an accompanying text
+func isEmptyMessage(message *chattypes.Message) bool {
+	return strings.TrimSpace(message.Content) == "" && message.ToolCall == nil
+}
+
+func messageTurn(message *chattypes.Message) turn {
+	content := message.Content
+	switch message.GetRole() {
+	case chattypes.MessageRoleAssistant:
+		// answers are saved HTML escaped, see llmResponseToText
+		content = html.UnescapeString(content)
+		if message.ToolCall != nil {
+			content = toolCallTurn(content, message.ToolCall)
+		}
+	case chattypes.MessageRoleTool:
+		content = toolResultTurn(html.UnescapeString(content))
+	}
+
+	return turn{
+		Role:      message.GetRole(),
+		Content:   content,
+		MessageId: message.Id,
+		SummaryOf: message.SummaryOf,
+	}
+}
+
+/*
+roleTemplates are the role templates of the model overridden
+by the ones of the thread template and the one of the prompt.
+*/
+func roleTemplates(
+	model *modeltypes.Model,
+	tpl *renderedTemplate,
+	currentPrompt *prompttypes.Prompt,
+) modeltypes.RoleTemplates {
+	templates := model.GetRoleTemplates()
+
+	if tpl != nil {
+		if tpl.RoleTemplates.System != "" {
+			templates.System = tpl.RoleTemplates.System
+		}
+		if tpl.RoleTemplates.User != "" {
+			templates.User = tpl.RoleTemplates.User
+		}
+		if tpl.RoleTemplates.Assistant != "" {
+			templates.Assistant = tpl.RoleTemplates.Assistant
+		}
+	}
+
+	// a template sent with the prompt takes precedence
+	if currentPrompt.Template != "" {
+		templates.User = currentPrompt.Template
+	}
+
+	return templates
+}
+
+func renderTurns(turns []turn, templates modeltypes.RoleTemplates) string {
+	var result strings.Builder
+
+	for _, t := range turns {
+		template := templates.User
+		switch t.Role {
+		case chattypes.MessageRoleSystem:
+			template = templates.System
+		case chattypes.MessageRoleAssistant:
+			template = templates.Assistant
+		case chattypes.MessageRoleTool:
+			// the chat formats of the models have no tool role,
+			// results are sent as user turns wrapped in result tags, see toolResultTurn
+			template = templates.User
+		}
+		if template == "" {
+			template = "{prompt}"
+		}
+
+		result.WriteString(strings.Replace(template, "{prompt}", t.Content, -1))
+	}
+
+	return result.String()
+}
@@ -0,0 +1,70 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+func TestHistoryTurns(t *testing.T) {
+	messages := []*chattypes.Message{
+		{Id: "1", UserId: "usr-1", Content: "What's a banana?"},
+		{Id: "2", Content: "A &quot;fruit&quot;."},
+		{Id: "3", Role: chattypes.MessageRoleSystem, Content: "Be brief."},
+		{Id: "4", UserId: "usr-1", Content: "   "},
+		{Id: "prompt-1", UserId: "usr-1", Content: "And an apple?"},
+	}
+
+	turns := historyTurns(messages, "prompt-1", 10)
+	assert.Equal(t, []turn{
+		{Role: chattypes.MessageRoleUser, Content: "What's a banana?", MessageId: "1"},
+		{Role: chattypes.MessageRoleAssistant, Content: `A "fruit".`, MessageId: "2"},
+		{Role: chattypes.MessageRoleSystem, Content: "Be brief.", MessageId: "3"},
+	}, turns)
+
+	turns = historyTurns(messages, "prompt-1", 1)
+	assert.Equal(t, []turn{
+		{Role: chattypes.MessageRoleSystem, Content: "Be brief.", MessageId: "3"},
+	}, turns)
+
+	messages = append(messages, &chattypes.Message{
+		Id:        "5",
+		Role:      chattypes.MessageRoleSystem,
+		Content:   "The user asked about bananas.",
+		SummaryOf: []string{"1", "2"},
+	})
+	turns = historyTurns(messages, "prompt-1", 10)
+	assert.Equal(t, []turn{
+		{Role: chattypes.MessageRoleSystem, Content: "Be brief.", MessageId: "3"},
+		{Role: chattypes.MessageRoleSystem, Content: "The user asked about bananas.", MessageId: "5", SummaryOf: []string{"1", "2"}},
+	}, turns)
+}
+
+func TestRenderTurns(t *testing.T) {
+	templates := modeltypes.RoleTemplates{
+		System:    "[INST] {prompt} [/INST]",
+		User:      "[INST] {prompt} [/INST]",
+		Assistant: " {prompt}</s>",
+	}
+
+	rendered := renderTurns([]turn{
+		{Role: chattypes.MessageRoleUser, Content: "Hi"},
+		{Role: chattypes.MessageRoleAssistant, Content: "Hello!"},
+		{Role: chattypes.MessageRoleUser, Content: "How are you?"},
+	}, templates)
+
+	assert.Equal(t, "[INST] Hi [/INST] Hello!</s>[INST] How are you? [/INST]", rendered)
+}
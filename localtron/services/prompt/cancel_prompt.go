@@ -0,0 +1,111 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	"github.com/singulatron/singulatron/localtron/logger"
+
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+var ErrPromptNotFound = errors.New("prompt not found")
+
+/*
+Cancel cancels a prompt of a user.
+Prompts waiting in the queue are marked as canceled right away,
+prompts being processed are aborted and their partial answer is saved,
+see processPrompt.
+*/
+func (p *PromptService) Cancel(userId string, promptId string) error {
+	p.cancelMutex.Lock()
+	defer p.cancelMutex.Unlock()
+
+	prompt, found, err := p.promptsStore.Query(
+		datastore.Id(promptId),
+	).FindOne()
+	if err != nil {
+		return err
+	}
+	if !found || prompt.UserId != userId {
+		return ErrPromptNotFound
+	}
+
+	if cancel, ok := p.cancelFuncs[promptId]; ok {
+		logger.Info("Canceling running prompt",
+			slog.String("promptId", promptId),
+		)
+		cancel()
+		return nil
+	}
+
+	if prompt.Status != prompttypes.PromptStatusScheduled &&
+		prompt.Status != prompttypes.PromptStatusErrored {
+		return nil
+	}
+
+	logger.Info("Canceling scheduled prompt",
+		slog.String("promptId", promptId),
+	)
+
+	prompt.Status = prompttypes.PromptStatusCanceled
+	err = p.promptsStore.Query(
+		datastore.Id(promptId),
+	).Update(prompt)
+	if err != nil {
+		return err
+	}
+
+	p.firehoseService.Publish(prompttypes.EventPromptCanceled{
+		PromptId: prompt.Id,
+		ThreadId: prompt.ThreadId,
+	})
+
+	return nil
+}
+
+/*
+startCancelable registers a cancelable context for a prompt about to be processed.
+Returns false if the prompt got canceled since it was selected from the queue.
+*/
+func (p *PromptService) startCancelable(promptId string) (context.Context, bool, error) {
+	p.cancelMutex.Lock()
+	defer p.cancelMutex.Unlock()
+
+	prompt, found, err := p.promptsStore.Query(
+		datastore.Id(promptId),
+	).FindOne()
+	if err != nil {
+		return nil, false, err
+	}
+	if !found || prompt.Status == prompttypes.PromptStatusCanceled {
+		return nil, false, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancelFuncs[promptId] = cancel
+
+	return ctx, true, nil
+}
+
+func (p *PromptService) finishCancelable(promptId string) {
+	p.cancelMutex.Lock()
+	defer p.cancelMutex.Unlock()
+
+	if cancel, ok := p.cancelFuncs[promptId]; ok {
+		cancel()
+		delete(p.cancelFuncs, promptId)
+	}
+}
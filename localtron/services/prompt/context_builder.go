@@ -0,0 +1,249 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"errors"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+const (
+	// tokens kept free in the context window for the answer
+	defaultCompletionReserve = 512
+	defaultKeepLastMessages  = 6
+	// a summary can take up at most 1/summaryShare of the context window
+	summaryShare = 4
+)
+
+var ErrPromptTooLong = errors.New("prompt does not fit the context window of the model")
+
+/*
+contextBuilder decides which turns of a conversation
+are sent to a model so the prompt fits its context window.
+*/
+type contextBuilder struct {
+	tokenizer     Tokenizer
+	templates     modeltypes.RoleTemplates
+	contextLength int
+	// reserve is the number of tokens kept free for the answer
+	reserve  int
+	strategy prompttypes.ContextStrategy
+	// keepLast is the N of the keep-system-last-n strategy
+	keepLast int
+	// summarize turns a list of turns into a short summary,
+	// only used by the summarize strategy
+	summarize func(turns []turn) (string, error)
+	// pinned turns are sent before the history and are never
+	// dropped or summarized, eg. the system prompt of a template
+	pinned []turn
+}
+
+type builtContext struct {
+	Turns []turn
+	// Summary replaces the Summarized turns when
+	// the summarize strategy kicked in
+	Summary    *turn
+	Summarized []turn
+}
+
+/*
+build fits the history before the current turns into the context window.
+The current turns are the prompt being answered and its tool steps,
+they are never dropped.
+*/
+func (cb *contextBuilder) build(history []turn, current []turn) (*builtContext, error) {
+	budget := cb.contextLength - cb.reserve
+
+	for _, t := range cb.pinned {
+		tokens, err := cb.count(t)
+		if err != nil {
+			return nil, err
+		}
+		budget -= tokens
+	}
+
+	built, err := cb.buildHistory(history, current, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cb.pinned) > 0 {
+		built.Turns = append(append([]turn{}, cb.pinned...), built.Turns...)
+	}
+
+	return built, nil
+}
+
+// buildHistory fits the history and the current turns into the budget
+func (cb *contextBuilder) buildHistory(history []turn, current []turn, budget int) (*builtContext, error) {
+	currentTokens := 0
+	for _, t := range current {
+		tokens, err := cb.count(t)
+		if err != nil {
+			return nil, err
+		}
+		currentTokens += tokens
+	}
+	if currentTokens > budget {
+		return nil, ErrPromptTooLong
+	}
+	budget -= currentTokens
+
+	var err error
+	counts := make([]int, len(history))
+	total := 0
+	for i, t := range history {
+		counts[i], err = cb.count(t)
+		if err != nil {
+			return nil, err
+		}
+		total += counts[i]
+	}
+
+	if total <= budget {
+		return &builtContext{
+			Turns: append(history, current...),
+		}, nil
+	}
+
+	switch cb.strategy {
+	case prompttypes.ContextStrategyKeepSystemLastN:
+		keepLast := cb.keepLast
+		if keepLast <= 0 {
+			keepLast = defaultKeepLastMessages
+		}
+		kept, keptCounts := keepSystemAndLast(history, counts, keepLast)
+		kept, _ = trimTurns(kept, keptCounts, budget, true)
+
+		return &builtContext{
+			Turns: append(kept, current...),
+		}, nil
+
+	case prompttypes.ContextStrategySummarize:
+		if cb.summarize == nil {
+			return nil, errors.New("no summarizer for the summarize strategy")
+		}
+
+		recent, older := trimTurns(history, counts, budget-cb.contextLength/summaryShare, false)
+		if len(older) == 0 {
+			return &builtContext{
+				Turns: append(recent, current...),
+			}, nil
+		}
+
+		summary, err := cb.summarize(older)
+		if err != nil {
+			return nil, err
+		}
+		summaryTurn := turn{
+			Role:    chattypes.MessageRoleSystem,
+			Content: "Summary of the earlier conversation: " + summary,
+		}
+		summaryTokens, err := cb.count(summaryTurn)
+		if err != nil {
+			return nil, err
+		}
+
+		// the summary might have turned out longer than planned for
+		recent, _ = trimTurns(recent, counts[len(older):], budget-summaryTokens, false)
+
+		turns := append([]turn{summaryTurn}, recent...)
+		return &builtContext{
+			Turns:      append(turns, current...),
+			Summary:    &summaryTurn,
+			Summarized: older,
+		}, nil
+	}
+
+	kept, _ := trimTurns(history, counts, budget, false)
+	return &builtContext{
+		Turns: append(kept, current...),
+	}, nil
+}
+
+func (cb *contextBuilder) count(t turn) (int, error) {
+	return cb.tokenizer.CountTokens(renderTurns([]turn{t}, cb.templates))
+}
+
+/*
+trimTurns drops the oldest turns until the rest fits the budget.
+When keepSystem is true system turns are only dropped if
+nothing else is left to drop.
+Returns the kept and the dropped turns, both in their original order.
+*/
+func trimTurns(turns []turn, counts []int, budget int, keepSystem bool) (kept []turn, dropped []turn) {
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+
+	keep := make([]bool, len(turns))
+	for i := range keep {
+		keep[i] = true
+	}
+
+	for _, pass := range []bool{keepSystem, false} {
+		for i := range turns {
+			if total <= budget {
+				break
+			}
+			if !keep[i] {
+				continue
+			}
+			if pass && turns[i].Role == chattypes.MessageRoleSystem {
+				continue
+			}
+			keep[i] = false
+			total -= counts[i]
+		}
+	}
+
+	for i, t := range turns {
+		if keep[i] {
+			kept = append(kept, t)
+		} else {
+			dropped = append(dropped, t)
+		}
+	}
+
+	return kept, dropped
+}
+
+// keepSystemAndLast keeps all system turns and the last n other turns
+func keepSystemAndLast(turns []turn, counts []int, n int) ([]turn, []int) {
+	keptTurns := []turn{}
+	keptCounts := []int{}
+
+	nonSystemSeen := 0
+	keep := make([]bool, len(turns))
+	for i := len(turns) - 1; i >= 0; i-- {
+		if turns[i].Role == chattypes.MessageRoleSystem {
+			keep[i] = true
+			continue
+		}
+		if nonSystemSeen < n {
+			keep[i] = true
+			nonSystemSeen++
+		}
+	}
+
+	for i := range turns {
+		if keep[i] {
+			keptTurns = append(keptTurns, turns[i])
+			keptCounts = append(keptCounts, counts[i])
+		}
+	}
+
+	return keptTurns, keptCounts
+}
@@ -0,0 +1,153 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+// wordTokenizer counts every word as a token
+type wordTokenizer struct{}
+
+func (w wordTokenizer) CountTokens(text string) (int, error) {
+	return len(strings.Fields(text)), nil
+}
+
+func contents(turns []turn) []string {
+	ret := []string{}
+	for _, t := range turns {
+		ret = append(ret, t.Content)
+	}
+	return ret
+}
+
+func TestContextBuilder(t *testing.T) {
+	templates := modeltypes.RoleTemplates{
+		System:    "{prompt}",
+		User:      "{prompt}",
+		Assistant: "{prompt}",
+	}
+
+	history := []turn{
+		{Role: chattypes.MessageRoleSystem, Content: "be very brief", MessageId: "1"},
+		{Role: chattypes.MessageRoleUser, Content: "one two three", MessageId: "2"},
+		{Role: chattypes.MessageRoleAssistant, Content: "four five six", MessageId: "3"},
+		{Role: chattypes.MessageRoleUser, Content: "seven eight", MessageId: "4"},
+		{Role: chattypes.MessageRoleAssistant, Content: "nine ten", MessageId: "5"},
+	}
+	current := turn{Role: chattypes.MessageRoleUser, Content: "eleven twelve"}
+
+	t.Run("everything fits", func(t *testing.T) {
+		cb := &contextBuilder{
+			tokenizer:     wordTokenizer{},
+			templates:     templates,
+			contextLength: 100,
+			reserve:       10,
+		}
+		built, err := cb.build(history, []turn{current})
+		require.NoError(t, err)
+		assert.Equal(t, 6, len(built.Turns))
+		assert.Nil(t, built.Summary)
+	})
+
+	t.Run("prompt too long", func(t *testing.T) {
+		cb := &contextBuilder{
+			tokenizer:     wordTokenizer{},
+			templates:     templates,
+			contextLength: 3,
+			reserve:       2,
+		}
+		_, err := cb.build(history, []turn{current})
+		require.Equal(t, ErrPromptTooLong, err)
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		cb := &contextBuilder{
+			tokenizer:     wordTokenizer{},
+			templates:     templates,
+			contextLength: 10,
+			reserve:       2,
+			strategy:      prompttypes.ContextStrategyDropOldest,
+		}
+		built, err := cb.build(history, []turn{current})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"seven eight", "nine ten", "eleven twelve"}, contents(built.Turns))
+	})
+
+	t.Run("keep system and last n", func(t *testing.T) {
+		cb := &contextBuilder{
+			tokenizer:     wordTokenizer{},
+			templates:     templates,
+			contextLength: 15,
+			reserve:       2,
+			strategy:      prompttypes.ContextStrategyKeepSystemLastN,
+			keepLast:      2,
+		}
+		built, err := cb.build(history, []turn{current})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"be very brief", "seven eight", "nine ten", "eleven twelve"}, contents(built.Turns))
+
+		cb.contextLength = 10
+		built, err = cb.build(history, []turn{current})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"be very brief", "nine ten", "eleven twelve"}, contents(built.Turns))
+	})
+
+	t.Run("pinned turns are kept", func(t *testing.T) {
+		cb := &contextBuilder{
+			tokenizer:     wordTokenizer{},
+			templates:     templates,
+			contextLength: 9,
+			reserve:       2,
+			strategy:      prompttypes.ContextStrategyDropOldest,
+			pinned: []turn{
+				{Role: chattypes.MessageRoleSystem, Content: "you count"},
+			},
+		}
+		built, err := cb.build(history, []turn{current})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"you count", "nine ten", "eleven twelve"}, contents(built.Turns))
+	})
+
+	t.Run("summarize", func(t *testing.T) {
+		var summarized []turn
+		cb := &contextBuilder{
+			tokenizer:     wordTokenizer{},
+			templates:     templates,
+			contextLength: 16,
+			reserve:       2,
+			strategy:      prompttypes.ContextStrategySummarize,
+			summarize: func(turns []turn) (string, error) {
+				summarized = turns
+				return "counting", nil
+			},
+		}
+		built, err := cb.build(history, []turn{current})
+		require.NoError(t, err)
+		require.NotNil(t, built.Summary)
+		assert.Equal(t, summarized, built.Summarized)
+		assert.Equal(t, []string{"be very brief", "one two three"}, contents(built.Summarized))
+		assert.Equal(t, []string{
+			"Summary of the earlier conversation: counting",
+			"seven eight",
+			"nine ten",
+			"eleven twelve",
+		}, contents(built.Turns))
+	})
+}
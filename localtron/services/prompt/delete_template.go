@@ -0,0 +1,39 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"github.com/singulatron/singulatron/localtron/datastore"
+	usertypes "github.com/singulatron/singulatron/localtron/services/user/types"
+)
+
+/*
+DeleteTemplate deletes a template of the user, admins can delete any template.
+Threads using the template are answered without it from then on.
+*/
+func (p *PromptService) DeleteTemplate(user *usertypes.User, templateId string) error {
+	tpl, found, err := p.templatesStore.Query(
+		datastore.Id(templateId),
+	).FindOne()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrTemplateNotFound
+	}
+	if !canEditTemplate(user, tpl) {
+		return ErrUnauthorized
+	}
+
+	return p.templatesStore.Query(
+		datastore.Id(templateId),
+	).Delete()
+}
@@ -12,7 +12,12 @@ package promptendpoints
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+
+	"github.com/singulatron/singulatron/localtron/clients/llm"
 
 	promptservice "github.com/singulatron/singulatron/localtron/services/prompt"
 	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
@@ -49,9 +54,45 @@ func Add(
 	}
 	defer r.Body.Close()
 
+	if req.Prompt.Parameters != nil {
+		err = req.Prompt.Parameters.Validate()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.Prompt.ResponseSchema != nil {
+		_, err = llm.JSONSchemaToGrammar(req.Prompt.ResponseSchema)
+		if err != nil {
+			http.Error(w, "invalid response schema: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.Prompt.Priority > prompttypes.PromptPriorityNormal {
+		err = userService.IsAuthorized(prompttypes.PermissionPromptPrioritize.Id, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
 	req.Prompt.UserId = user.Id
 
 	err = promptService.AddPrompt(req.Prompt)
+	quotaErr := &promptservice.QuotaExceededError{}
+	if errors.As(err, &quotaErr) {
+		if quotaErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(quotaErr.RetryAfter.Seconds()))))
+		}
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if errors.Is(err, promptservice.ErrInvalidPrompt) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -50,8 +50,15 @@ func List(
 		return
 	}
 
+	positions, err := promptService.QueuePositions()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	bs, _ := json.Marshal(prompttypes.ListPromptsResponse{
-		Prompts: prompts,
+		Prompts:        prompts,
+		QueuePositions: positions,
 	})
 	w.Write(bs)
 }
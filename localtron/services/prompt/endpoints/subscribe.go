@@ -12,10 +12,11 @@ package promptendpoints
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
-	"github.com/singulatron/singulatron/localtron/clients/llm"
 	"github.com/singulatron/singulatron/localtron/logger"
 
 	promptservice "github.com/singulatron/singulatron/localtron/services/prompt"
@@ -42,10 +43,20 @@ func Subscribe(
 		return
 	}
 
+	// set by clients reconnecting to a stream, see StreamManager.Subscribe
+	var lastEventId int64
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		lastEventId, err = strconv.ParseInt(header, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID header", http.StatusBadRequest)
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "text/event-stream")
 
-	subscriber := make(chan *llm.CompletionResponse)
-	promptService.StreamManager.Subscribe(threadId, subscriber)
+	subscriber := make(promptservice.SubscriberChan, subscriberBufferSize)
+	missed := promptService.StreamManager.Subscribe(threadId, subscriber, lastEventId)
 	defer promptService.StreamManager.Unsubscribe(threadId, subscriber)
 
 	// Use context to handle client disconnection
@@ -55,23 +66,42 @@ func Subscribe(
 		promptService.StreamManager.Unsubscribe(threadId, subscriber)
 	}()
 
-	for resp := range subscriber {
-		resp.Model = "" // Redact model from response
-		jsonResp, err := json.Marshal(resp)
-		if err != nil {
-			log.Printf("Failed to marshal JSON: %v", err)
-			continue
+	for _, event := range missed {
+		if !writeStreamEvent(w, event) {
+			return
 		}
+	}
 
-		if _, writeErr := w.Write([]byte("data: " + string(jsonResp) + "\n")); writeErr != nil {
-			log.Printf("Failed to write streaming response: %v", writeErr)
+	for event := range subscriber {
+		if !writeStreamEvent(w, event) {
 			break // Exit the loop on write errors
 		}
+	}
+}
 
-		if flusher, ok := w.(http.Flusher); ok {
-			flusher.Flush()
-		} else {
-			logger.Warn("Warning: ResponseWriter does not support flushing, streaming might be delayed")
-		}
+// the stream manager drops responses for subscribers which can't keep up
+const subscriberBufferSize = 256
+
+func writeStreamEvent(w http.ResponseWriter, event *promptservice.StreamEvent) bool {
+	resp := *event.Response
+	resp.Model = "" // Redact model from response
+	jsonResp, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Failed to marshal JSON: %v", err)
+		return true
+	}
+
+	_, writeErr := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", event.Seq, jsonResp)
+	if writeErr != nil {
+		log.Printf("Failed to write streaming response: %v", writeErr)
+		return false
+	}
+
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	} else {
+		logger.Warn("Warning: ResponseWriter does not support flushing, streaming might be delayed")
 	}
+
+	return true
 }
@@ -0,0 +1,83 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/clients/stable_diffusion"
+	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+var ErrImageAssetNotFound = errors.New("image asset not found")
+
+const defaultImageContentType = "image/png"
+
+/*
+imageDataURL returns an image asset as a data URL to send to image generating platforms.
+Returns nil for an empty asset id.
+*/
+func (p *PromptService) imageDataURL(assetId string) (*string, error) {
+	if assetId == "" {
+		return nil, nil
+	}
+
+	asset, content, err := p.appService.OpenAsset(assetId)
+	if errors.Is(err, chatservice.ErrAssetNotFound) {
+		return nil, fmt.Errorf("%w: '%v'", ErrImageAssetNotFound, assetId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading image asset")
+	}
+
+	contentType := asset.Type
+	if contentType == "" {
+		contentType = defaultImageContentType
+	}
+
+	url := stable_diffusion.ImageDataURL(contentType, base64.StdEncoding.EncodeToString(data))
+	return &url, nil
+}
+
+// saveImage downloads a generated image into the asset store.
+func (p *PromptService) saveImage(imgUrl string) (*chattypes.Asset, error) {
+	image, contentType, err := stable_diffusion.GetImage(imgUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer image.Close()
+
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = defaultImageContentType
+	}
+
+	asset, err := p.appService.SaveAsset(image, contentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "error saving image")
+	}
+	if asset.Size == 0 {
+		return nil, errors.New("empty image acquired")
+	}
+
+	return asset, nil
+}
@@ -24,6 +24,24 @@ type ListPromptOptions struct {
 	LastRunAfter time.Time
 }
 
+/*
+QueuePositions tells the place of the waiting prompts
+in the queue of their model by prompt id, starting from 1.
+*/
+func (p *PromptService) QueuePositions() (map[string]int, error) {
+	prompts, err := p.promptsStore.Query(
+		datastore.All(),
+	).OrderBy("createdAt", false).Find()
+	if err != nil {
+		return nil, err
+	}
+
+	p.runMutex.Lock()
+	defer p.runMutex.Unlock()
+
+	return queuePositions(prompts, p.runningPrompts), nil
+}
+
 func (p *PromptService) ListPrompts(options *ListPromptOptions) ([]*prompttypes.Prompt, error) {
 	return p.promptsStore.Query(
 		datastore.Equal("status", options.Statuses),
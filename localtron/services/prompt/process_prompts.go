@@ -11,8 +11,11 @@
 package promptservice
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"math"
+	"math/rand"
 	"strings"
 	"sync"
 	"time"
@@ -28,12 +31,15 @@ import (
 	apptypes "github.com/singulatron/singulatron/localtron/services/chat/types"
 	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
 	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+	tooltypes "github.com/singulatron/singulatron/localtron/services/tool/types"
 )
 
 const (
 	maxRetries    = 5
 	baseDelay     = 1 * time.Second
 	promptTimeout = 1 * time.Minute
+	// llama-cpp caps max tokens to what is left of the context window
+	unlimitedMaxTokens = 1000000
 )
 
 // a blocking method, call it in a goroutine
@@ -47,77 +53,108 @@ func (p *PromptService) processPrompts() {
 		case <-p.trigger:
 		}
 
-		err := p.processNextPrompt()
+		err := p.processNextPrompts()
 		if err != nil {
-			logger.Error("Error processing prompt",
+			logger.Error("Error processing prompts",
 				slog.String("error", err.Error()),
 			)
 		}
 	}
 }
 
-func (p *PromptService) processNextPrompt() error {
+// processNextPrompts starts processing the prompts that are next in the queue
+func (p *PromptService) processNextPrompts() error {
 	p.runMutex.Lock()
 	defer p.runMutex.Unlock()
 
-	runningPrompts, err := p.promptsStore.Query(
-		datastore.Equal("status", prompttypes.PromptStatusRunning),
-	).Find()
+	prompts, err := p.promptsStore.Query(
+		datastore.All(),
+	).OrderBy("createdAt", false).Find()
 	if err != nil {
 		return err
 	}
 
-	hasRunning := false
-	runningPromptId := ""
-	for _, runningPrompt := range runningPrompts {
-		if runningPrompt.LastRun.Before(time.Now().Add(-promptTimeout)) {
+	for _, prompt := range prompts {
+		// prompts processed by this instance are never timed out,
+		// an image generation can take much longer than a chat answer.
+		// Their status is changed by the worker goroutines so it must not be read here.
+		if p.runningPrompts[prompt.Id] || prompt.Status != prompttypes.PromptStatusRunning {
+			continue
+		}
+		if prompt.LastRun.Before(time.Now().Add(-promptTimeout)) {
 			logger.Info("Setting prompt as timed out",
-				slog.String("promptId", runningPrompt.Id),
+				slog.String("promptId", prompt.Id),
 			)
 
-			runningPrompt.Status = prompttypes.PromptStatusErrored
-			runningPrompt.Error = "timed out"
+			setFailed(prompt, "timed out")
 			err = p.promptsStore.Query(
-				datastore.Id(runningPrompt.Id),
-			).Update(runningPrompt)
+				datastore.Id(prompt.Id),
+			).Update(prompt)
 			if err != nil {
 				return err
 			}
-			continue
-		}
-		hasRunning = true
-		runningPromptId = runningPrompt.Id
-	}
 
-	if hasRunning {
-		logger.Debug("Prompt is already running",
-			slog.String("promptId", runningPromptId),
-		)
-		return nil
+			p.firehoseService.Publish(prompttypes.EventPromptProcessingFinished{
+				PromptId: prompt.Id,
+				Status:   prompt.Status,
+				Error:    prompt.Error,
+			})
+		}
 	}
 
-	currentPrompt, err := selectPrompt(p.promptsStore)
+	conf, err := p.configService.GetConfig()
 	if err != nil {
 		return err
 	}
-	if currentPrompt == nil {
-		return nil
+
+	for _, prompt := range selectPrompts(prompts, p.runningPrompts, conf.Prompt.ConcurrencyPerModel) {
+		p.runningPrompts[prompt.Id] = true
+
+		go func(prompt *prompttypes.Prompt) {
+			err := p.processPrompt(prompt)
+			if err != nil {
+				logger.Error("Error processing prompt",
+					slog.String("promptId", prompt.Id),
+					slog.String("error", err.Error()),
+				)
+			}
+
+			p.runMutex.Lock()
+			delete(p.runningPrompts, prompt.Id)
+			p.runMutex.Unlock()
+
+			// a slot got freed up for the next prompt
+			p.triggerPromptProcessing()
+		}(prompt)
 	}
 
-	return p.processPrompt(currentPrompt)
+	return nil
 }
 
 func (p *PromptService) processPrompt(currentPrompt *prompttypes.Prompt) (err error) {
+	ctx, ok, err := p.startCancelable(currentPrompt.Id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logger.Info("Prompt got canceled before processing",
+			slog.String("promptId", currentPrompt.Id),
+		)
+		return nil
+	}
+	defer p.finishCancelable(currentPrompt.Id)
+
 	defer func() {
 		if r := recover(); r != nil {
-			currentPrompt.Error = fmt.Sprintf("%v", r)
-			currentPrompt.Status = prompttypes.PromptStatusErrored
-			return
+			err = fmt.Errorf("panic: %v", r)
 		}
 
-		if err != nil {
-			currentPrompt.Error = err.Error()
-			currentPrompt.Status = prompttypes.PromptStatusErrored
+		canceled := errors.Is(ctx.Err(), context.Canceled)
+		if canceled {
+			currentPrompt.Error = ""
+			currentPrompt.Status = prompttypes.PromptStatusCanceled
+		} else if err != nil {
+			setFailed(currentPrompt, err.Error())
 		} else {
 			currentPrompt.Status = prompttypes.PromptStatusCompleted
 		}
@@ -127,6 +164,16 @@ func (p *PromptService) processPrompt(currentPrompt *prompttypes.Prompt) (err er
 			slog.String("status", string(currentPrompt.Status)),
 		)
 
+		if countsUsage(currentPrompt) {
+			usageErr := p.recordUsage(currentPrompt, time.Now())
+			if usageErr != nil {
+				logger.Error("Error recording prompt usage",
+					slog.String("promptId", currentPrompt.Id),
+					slog.String("error", usageErr.Error()),
+				)
+			}
+		}
+
 		err = p.promptsStore.Query(
 			datastore.Id(currentPrompt.Id),
 		).Update(currentPrompt)
@@ -136,6 +183,19 @@ func (p *PromptService) processPrompt(currentPrompt *prompttypes.Prompt) (err er
 				slog.String("error", err.Error()),
 			)
 		}
+
+		p.firehoseService.Publish(prompttypes.EventPromptProcessingFinished{
+			PromptId: currentPrompt.Id,
+			Status:   currentPrompt.Status,
+			Error:    currentPrompt.Error,
+		})
+
+		if canceled {
+			p.firehoseService.Publish(prompttypes.EventPromptCanceled{
+				PromptId: currentPrompt.Id,
+				ThreadId: currentPrompt.ThreadId,
+			})
+		}
 	}()
 
 	logger.Info("Picking up prompt from queue",
@@ -146,22 +206,27 @@ func (p *PromptService) processPrompt(currentPrompt *prompttypes.Prompt) (err er
 		PromptId: currentPrompt.Id,
 	})
 
-	defer p.firehoseService.Publish(prompttypes.EventPromptProcessingFinished{
-		PromptId: currentPrompt.Id,
-		Error:    errToString(err),
-	})
-
 	currentPrompt.LastRun = time.Now()
 	currentPrompt.Error = ""
 	currentPrompt.Status = prompttypes.PromptStatusRunning
 	currentPrompt.RunCount++
+	currentPrompt.Usage = nil
+
+	err = p.promptsStore.Query(
+		datastore.Id(currentPrompt.Id),
+	).Update(currentPrompt)
+	if err != nil {
+		return err
+	}
 
 	err = p.appService.AddMessage(&apptypes.Message{
 		// not a fan of taking the prompt id but at least it makes this idempotent
 		// in case prompts get retried over and over again
 		Id:        currentPrompt.Id,
 		ThreadId:  currentPrompt.ThreadId,
+		ParentId:  currentPrompt.ParentId,
 		UserId:    currentPrompt.UserId,
+		Role:      apptypes.MessageRoleUser,
 		Content:   currentPrompt.Prompt,
 		CreatedAt: time.Now(),
 	})
@@ -183,12 +248,14 @@ func (p *PromptService) processPrompt(currentPrompt *prompttypes.Prompt) (err er
 		stat.Address = "http://" + stat.Address
 	}
 
-	fullPrompt := currentPrompt.Prompt
-	if currentPrompt.Template != "" {
-		fullPrompt = strings.Replace(currentPrompt.Template, "{prompt}", currentPrompt.Prompt, -1)
-	}
+	currentPrompt.Usage = &prompttypes.Usage{}
+	generationStart := time.Now()
+
+	release := p.modelService.Use(currentPrompt.ModelId)
+	err = p.processPlatform(ctx, stat.Address, currentPrompt)
+	release()
 
-	err = p.processPlatform(stat.Address, fullPrompt, currentPrompt)
+	currentPrompt.Usage.GenerationMs = time.Since(generationStart).Milliseconds()
 
 	logger.Debug("Finished streaming LLM",
 		slog.String("error", fmt.Sprintf("%v", err)),
@@ -200,80 +267,152 @@ func (p *PromptService) processPrompt(currentPrompt *prompttypes.Prompt) (err er
 	return nil
 }
 
-func (p *PromptService) processPlatform(address string, fullPrompt string, currentPrompt *prompttypes.Prompt) error {
+/*
+setFailed marks a prompt as errored so it gets retried,
+or as abandoned once it ran out of retries.
+*/
+func setFailed(prompt *prompttypes.Prompt, errorMessage string) {
+	prompt.Error = errorMessage
+
+	retries := prompt.MaxRetries
+	if retries <= 0 {
+		retries = maxRetries
+	}
+
+	if prompt.RunCount >= retries {
+		prompt.Status = prompttypes.PromptStatusAbandoned
+		return
+	}
+
+	prompt.Status = prompttypes.PromptStatusErrored
+}
+
+func (p *PromptService) processPlatform(ctx context.Context, address string, currentPrompt *prompttypes.Prompt) error {
 	platform, err := p.modelService.GetPlatformByModelId(currentPrompt.ModelId)
 	if err != nil {
 		return err
 	}
 
 	switch platform.Id {
-	case modeltypes.PlatformLlamaCpp.Id:
-		return p.processLlamaCpp(address, fullPrompt, currentPrompt)
-	case modeltypes.PlatformStableDiffusion.Id:
-		return p.processStableDiffusion(address, fullPrompt, currentPrompt)
+	case modeltypes.PlatformIdLlamaCpp:
+		model, found, err := p.modelService.GetModel(currentPrompt.ModelId)
+		if err != nil {
+			return errors.Wrap(err, "error getting model")
+		}
+		if !found {
+			return errors.New("cannot find model")
+		}
+		params := model.GetParameters(currentPrompt.Parameters)
+
+		return p.processLlamaCppSteps(ctx, address, model, params, currentPrompt)
+	case modeltypes.PlatformIdStableDiffusion:
+		fullPrompt := currentPrompt.Prompt
+		if currentPrompt.Template != "" {
+			fullPrompt = strings.Replace(currentPrompt.Template, "{prompt}", currentPrompt.Prompt, -1)
+		}
+		return p.processStableDiffusion(ctx, address, fullPrompt, platform, currentPrompt)
 	}
 
 	return fmt.Errorf("cannot find platform %v", platform.Id)
 }
 
-func (p *PromptService) processStableDiffusion(address string, fullPrompt string, currentPrompt *prompttypes.Prompt) error {
+/*
+processStableDiffusion generates the images of a prompt with the image parameters
+of the prompt and saves them with the parameters, so they can be generated again.
+Images are refined instead of generated from scratch when the parameters
+have an input image, and only the masked part is painted over when they have a mask.
+*/
+func (p *PromptService) processStableDiffusion(
+	ctx context.Context,
+	address string,
+	fullPrompt string,
+	platform *modeltypes.Platform,
+	currentPrompt *prompttypes.Prompt,
+) error {
+	if platform.Image == nil {
+		return fmt.Errorf("platform '%v' does not generate images", platform.Id)
+	}
+	params, err := platform.Image.Resolve(currentPrompt.ImageParameters)
+	if err != nil {
+		return errors.Wrap(err, "invalid image parameters")
+	}
+	if params.Seed == nil {
+		seed := rand.Intn(math.MaxInt32)
+		params.Seed = &seed
+	}
+
+	inputImage, err := p.imageDataURL(params.InputAssetId)
+	if err != nil {
+		return errors.Wrap(err, "error getting input image")
+	}
+	mask, err := p.imageDataURL(params.MaskAssetId)
+	if err != nil {
+		return errors.Wrap(err, "error getting mask")
+	}
+
 	sd := stable_diffusion.Client{
 		Address: address,
 	}
 
 	req := stable_diffusion.PredictRequest{
-		FnIndex: 1,
+		FnIndex: stable_diffusion.FnIndexInference,
 		Params: stable_diffusion.StableDiffusionParams{
 			Prompt:        fullPrompt,
-			NumImages:     1,
-			Steps:         50,
-			Width:         512,
-			Height:        512,
-			GuidanceScale: 7.5,
-			Seed:          0,
+			NumImages:     params.NumImages,
+			Steps:         params.Steps,
+			Width:         params.Width,
+			Height:        params.Height,
+			GuidanceScale: *params.GuidanceScale,
+			Seed:          *params.Seed,
 			Flag1:         false,
 			Flag2:         false,
-			Scheduler:     "PNDM",
-			Rate:          0.25,
+			Scheduler:     params.Scheduler,
+			Strength:      *params.Strength,
+			InputImage:    inputImage,
+			Mask:          mask,
 		},
 	}
 	req.ConvertParamsToData()
 
-	rsp, err := sd.Predict(req)
+	rsp, err := sd.Predict(ctx, req)
 	if err != nil {
 		return err
 	}
 
-	if len(rsp.Data) == 0 {
+	if len(rsp.Data) == 0 || len(rsp.Data[0].FileData) == 0 {
 		return errors.New("no image in response")
 	}
 
-	imgUrl := stable_diffusion.FileURL(address, rsp.Data[0].FileData[0].Name)
+	assets := []*apptypes.Asset{}
+	for _, file := range rsp.Data[0].FileData {
+		imgUrl := stable_diffusion.FileURL(address, file.Name)
 
-	base64String, err := stable_diffusion.GetImageAsBase64(imgUrl)
-	if err != nil {
-		return err
-	}
-	if len(base64String) == 0 {
-		return errors.New("empty image acquired")
+		asset, err := p.saveImage(imgUrl)
+		if err != nil {
+			return err
+		}
+
+		assets = append(assets, asset)
 	}
 
-	asset := &apptypes.Asset{
-		Id:      uuid.New().String(),
-		Content: base64String,
+	assetIds := []string{}
+	for _, asset := range assets {
+		assetIds = append(assetIds, asset.Id)
 	}
-	err = p.appService.UpsertAssets([]*apptypes.Asset{
-		asset,
-	})
-	if err != nil {
-		return err
+
+	content := "Sure, here is your image"
+	if len(assets) > 1 {
+		content = "Sure, here are your images"
 	}
 
 	err = p.appService.AddMessage(&apptypes.Message{
-		Id:       uuid.New().String(),
-		ThreadId: currentPrompt.ThreadId,
-		Content:  "Sure, here is your image",
-		AssetIds: []string{asset.Id},
+		Id:              uuid.New().String(),
+		ThreadId:        currentPrompt.ThreadId,
+		ParentId:        currentPrompt.Id,
+		Role:            apptypes.MessageRoleAssistant,
+		Content:         content,
+		AssetIds:        assetIds,
+		ImageParameters: &params,
 	})
 	if err != nil {
 		logger.Error("Error when saving chat message after image generation",
@@ -284,7 +423,80 @@ func (p *PromptService) processStableDiffusion(address string, fullPrompt string
 	return nil
 }
 
-func (p *PromptService) processLlamaCpp(address string, fullPrompt string, currentPrompt *prompttypes.Prompt) error {
+/*
+processLlamaCppSteps answers a prompt, running the tools the model calls
+and answering again with their results until the model gives a final answer.
+The last step allowed by MaxToolSteps is answered without tools.
+*/
+func (p *PromptService) processLlamaCppSteps(
+	ctx context.Context,
+	address string,
+	model *modeltypes.Model,
+	params modeltypes.SamplingParameters,
+	currentPrompt *prompttypes.Prompt,
+) error {
+	tools, err := p.toolService.GetTools(currentPrompt.ToolIds)
+	if err != nil {
+		return errors.Wrap(err, "error getting tools")
+	}
+
+	citations, err := p.retrieve(currentPrompt)
+	if err != nil {
+		return errors.Wrap(err, "error retrieving documents")
+	}
+
+	maxSteps := currentPrompt.MaxToolSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultMaxToolSteps
+	}
+
+	// every step answers the message saved by the previous one,
+	// not whatever is selected in the thread meanwhile
+	parentId := currentPrompt.Id
+
+	for step := 0; ; step++ {
+		stepTools := tools
+		if step >= maxSteps {
+			stepTools = nil
+		}
+
+		stepParams := params
+		if len(stepTools) > 0 {
+			stepParams.Stop = append(append([]string{}, params.Stop...), toolCallEnd)
+		}
+
+		fullPrompt, err := p.buildFullPrompt(ctx, address, model, stepParams, currentPrompt, stepTools, citations)
+		if err != nil {
+			return errors.Wrap(err, "error building prompt")
+		}
+
+		answer, err := p.processLlamaCpp(ctx, address, fullPrompt, stepParams, currentPrompt, parentId, stepTools, citations)
+		if err != nil || answer == nil || answer.ToolCall == nil {
+			return err
+		}
+
+		parentId, err = p.runToolCall(ctx, currentPrompt, answer.Id, stepTools, answer.ToolCall)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+/*
+processLlamaCpp streams an answer and saves it as a reply to the parent message
+with the citations it was given.
+Returns the saved answer, nil for structured and canceled ones.
+*/
+func (p *PromptService) processLlamaCpp(
+	ctx context.Context,
+	address string,
+	fullPrompt string,
+	params modeltypes.SamplingParameters,
+	currentPrompt *prompttypes.Prompt,
+	parentId string,
+	tools []*tooltypes.Tool,
+	citations []*apptypes.Citation,
+) (*apptypes.Message, error) {
 	llmClient := llm.Client{
 		LLMAddress: address,
 	}
@@ -316,32 +528,196 @@ func (p *PromptService) processLlamaCpp(address string, fullPrompt string, curre
 
 	done <- true
 
-	err := llmClient.PostCompletionsStreamed(llm.PostCompletionsRequest{
-		Prompt:    fullPrompt,
-		Stream:    true,
-		MaxTokens: 1000000,
+	maxTokens := unlimitedMaxTokens
+	if params.MaxTokens != nil {
+		maxTokens = *params.MaxTokens
+	}
+
+	var grammar string
+	if currentPrompt.ResponseSchema != nil {
+		var err error
+		grammar, err = llm.JSONSchemaToGrammar(currentPrompt.ResponseSchema)
+		if err != nil {
+			return nil, errors.Wrap(err, "error converting response schema")
+		}
+	}
+
+	var usage llm.Usage
+	var streamedTokens int
+	var answer *apptypes.Message
+
+	err := llmClient.PostCompletionsStreamed(ctx, llm.PostCompletionsRequest{
+		Prompt:      fullPrompt,
+		Stream:      true,
+		MaxTokens:   maxTokens,
+		Temperature: params.Temperature,
+		TopP:        params.TopP,
+		TopK:        params.TopK,
+		Seed:        params.Seed,
+		Stop:        params.Stop,
+		Grammar:     grammar,
 	}, func(resp *llm.CompletionResponse) {
 		mu.Lock()
 		responseCount++
 		mu.Unlock()
 
+		if resp.Usage.CompletionTokens > 0 {
+			usage = resp.Usage
+		}
+		if len(resp.Choices) > 0 && resp.Choices[0].Text != "" {
+			// llama-cpp streams a token at a time
+			streamedTokens++
+		}
+
 		p.StreamManager.Broadcast(currentPrompt.ThreadId, resp)
 
-		if len(resp.Choices) > 0 && resp.Choices[0].FinishReason == "stop" {
-			err := p.appService.AddMessage(&apptypes.Message{
-				Id:       uuid.New().String(),
-				ThreadId: currentPrompt.ThreadId,
-				Content:  llmResponseToText(p.StreamManager.history[currentPrompt.ThreadId]),
-			})
+		// structured answers are saved once validated
+		if len(resp.Choices) > 0 && resp.Choices[0].FinishReason != "" &&
+			currentPrompt.ResponseSchema == nil {
+			var err error
+			answer, err = p.saveAnswer(currentPrompt.ThreadId, parentId, false, tools, citations)
 			if err != nil {
 				logger.Error("Error when saving chat message after broadcast",
 					slog.String("error", err.Error()))
 				return
 			}
+		}
+	})
 
-			delete(p.StreamManager.history, currentPrompt.ThreadId)
+	p.setTokenUsage(address, fullPrompt, usage, streamedTokens, currentPrompt)
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		// keep what has been streamed until the cancellation
+		_, saveErr := p.saveAnswer(currentPrompt.ThreadId, parentId, true, nil, citations)
+		if saveErr != nil {
+			logger.Error("Error when saving truncated chat message",
+				slog.String("error", saveErr.Error()))
 		}
+	} else if err != nil {
+		// a retry starts a new answer
+		p.StreamManager.FinishAnswer(currentPrompt.ThreadId)
+	} else if currentPrompt.ResponseSchema != nil {
+		err = p.saveStructuredAnswer(currentPrompt, parentId, citations)
+	}
+
+	return answer, err
+}
+
+/*
+saveStructuredAnswer validates the streamed answer against the response schema
+of the prompt and saves it. Invalid answers are dropped and an error is returned
+so the prompt gets retried.
+*/
+func (p *PromptService) saveStructuredAnswer(
+	currentPrompt *prompttypes.Prompt,
+	parentId string,
+	citations []*apptypes.Citation,
+) error {
+	responses := p.StreamManager.History(currentPrompt.ThreadId)
+
+	structured, err := parseStructuredAnswer(llmResponseToRawText(responses), currentPrompt.ResponseSchema)
+	if err != nil {
+		p.StreamManager.FinishAnswer(currentPrompt.ThreadId)
+		return errors.Wrap(err, "answer does not match the response schema")
+	}
+
+	err = p.appService.AddMessage(&apptypes.Message{
+		Id:         uuid.New().String(),
+		ThreadId:   currentPrompt.ThreadId,
+		ParentId:   parentId,
+		Role:       apptypes.MessageRoleAssistant,
+		Content:    llmResponseToText(responses),
+		Structured: structured,
+		Citations:  citations,
 	})
+	if err != nil {
+		return err
+	}
+
+	p.StreamManager.FinishAnswer(currentPrompt.ThreadId)
+
+	return nil
+}
+
+/*
+saveAnswer saves the responses streamed to a thread as an answer message.
+When tools are given the answer is checked for a tool call.
+*/
+func (p *PromptService) saveAnswer(
+	threadId string,
+	parentId string,
+	truncated bool,
+	tools []*tooltypes.Tool,
+	citations []*apptypes.Citation,
+) (*apptypes.Message, error) {
+	responses := p.StreamManager.History(threadId)
+	if len(responses) == 0 {
+		return nil, nil
+	}
+
+	message := &apptypes.Message{
+		Id:        uuid.New().String(),
+		ThreadId:  threadId,
+		ParentId:  parentId,
+		Role:      apptypes.MessageRoleAssistant,
+		Content:   llmResponseToText(responses),
+		Truncated: truncated,
+		Citations: citations,
+	}
+
+	if len(tools) > 0 {
+		text, call, ok := parseToolCall(llmResponseToRawText(responses))
+		if ok {
+			message.Content = escapeHtml(strings.TrimSpace(text))
+			message.ToolCall = call
+			// the final answer is the one citing the documents
+			message.Citations = nil
+		}
+	}
+
+	err := p.appService.AddMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	p.StreamManager.FinishAnswer(threadId)
+
+	return message, nil
+}
 
-	return err
+/*
+setTokenUsage adds the token counts of an answer to the prompt.
+Streamed responses usually come without usage, in which case
+the prompt is tokenized and the streamed tokens are counted.
+*/
+func (p *PromptService) setTokenUsage(
+	address string,
+	fullPrompt string,
+	usage llm.Usage,
+	streamedTokens int,
+	currentPrompt *prompttypes.Prompt,
+) {
+	if currentPrompt.Usage == nil {
+		currentPrompt.Usage = &prompttypes.Usage{}
+	}
+
+	// answers with tool calls take multiple steps
+	if usage.CompletionTokens > 0 {
+		currentPrompt.Usage.PromptTokens += usage.PromptTokens
+		currentPrompt.Usage.CompletionTokens += usage.CompletionTokens
+		return
+	}
+
+	currentPrompt.Usage.CompletionTokens += streamedTokens
+
+	// full prompts are not cached as they are different every time
+	promptTokens, err := newLlmTokenizer(address, "", nil).CountTokens(fullPrompt)
+	if err != nil {
+		logger.Warn("Error counting prompt tokens",
+			slog.String("promptId", currentPrompt.Id),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	currentPrompt.Usage.PromptTokens += promptTokens
 }
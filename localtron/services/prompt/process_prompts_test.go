@@ -0,0 +1,56 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+func TestSetFailed(t *testing.T) {
+	tests := []struct {
+		name           string
+		prompt         *prompttypes.Prompt
+		expectedStatus prompttypes.PromptStatus
+	}{
+		{
+			name:           "Retries left",
+			prompt:         &prompttypes.Prompt{RunCount: 1},
+			expectedStatus: prompttypes.PromptStatusErrored,
+		},
+		{
+			name:           "Out of default retries",
+			prompt:         &prompttypes.Prompt{RunCount: maxRetries},
+			expectedStatus: prompttypes.PromptStatusAbandoned,
+		},
+		{
+			name:           "Out of own retries",
+			prompt:         &prompttypes.Prompt{RunCount: 2, MaxRetries: 2},
+			expectedStatus: prompttypes.PromptStatusAbandoned,
+		},
+		{
+			name:           "Own retries above the default",
+			prompt:         &prompttypes.Prompt{RunCount: maxRetries, MaxRetries: maxRetries + 1},
+			expectedStatus: prompttypes.PromptStatusErrored,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFailed(tt.prompt, "model is not running")
+			assert.Equal(t, tt.expectedStatus, tt.prompt.Status)
+			assert.Equal(t, "model is not running", tt.prompt.Error)
+		})
+	}
+}
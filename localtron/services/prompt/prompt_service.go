@@ -11,31 +11,51 @@
 package promptservice
 
 import (
+	"context"
 	"sync"
 
 	"github.com/singulatron/singulatron/localtron/datastore"
 
 	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
 	configservice "github.com/singulatron/singulatron/localtron/services/config"
+	documentservice "github.com/singulatron/singulatron/localtron/services/document"
 	firehoseservice "github.com/singulatron/singulatron/localtron/services/firehose"
 	modelservice "github.com/singulatron/singulatron/localtron/services/model"
 	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
 	storefactoryservice "github.com/singulatron/singulatron/localtron/services/store_factory"
+	toolservice "github.com/singulatron/singulatron/localtron/services/tool"
 	userservice "github.com/singulatron/singulatron/localtron/services/user"
 )
 
 type PromptService struct {
+	configService   *configservice.ConfigService
 	userService     *userservice.UserService
 	modelService    *modelservice.ModelService
 	appService      *chatservice.ChatService
 	firehoseService *firehoseservice.FirehoseService
+	toolService     *toolservice.ToolService
+	documentService *documentservice.DocumentService
 
 	StreamManager *StreamManager
 
 	promptsStore datastore.DataStore[*prompttypes.Prompt]
+	usageStore   datastore.DataStore[*prompttypes.UsageStat]
+	// usageMutex guards the read-modify-write of usage stats
+	usageMutex  sync.Mutex
+	quotasStore datastore.DataStore[*prompttypes.Quota]
+	// quotaMutex makes checking the quota and adding a prompt atomic
+	quotaMutex     sync.Mutex
+	templatesStore datastore.DataStore[*prompttypes.Template]
+	tokenCounts    *tokenCountCache
 
 	runMutex sync.Mutex
 	trigger  chan bool
+	// runningPrompts are the ids of the prompts being processed, guarded by runMutex
+	runningPrompts map[string]bool
+
+	// cancelFuncs abort the prompts currently being processed
+	cancelFuncs map[string]context.CancelFunc
+	cancelMutex sync.Mutex
 }
 
 func NewPromptService(
@@ -44,24 +64,50 @@ func NewPromptService(
 	modelService *modelservice.ModelService,
 	appService *chatservice.ChatService,
 	firehoseService *firehoseservice.FirehoseService,
-
+	toolService *toolservice.ToolService,
+	documentService *documentservice.DocumentService,
 ) (*PromptService, error) {
 	promptsStore, err := storefactoryservice.GetStore[*prompttypes.Prompt]("prompts")
 	if err != nil {
 		return nil, err
 	}
 
+	usageStore, err := storefactoryservice.GetStore[*prompttypes.UsageStat]("promptUsage")
+	if err != nil {
+		return nil, err
+	}
+
+	quotasStore, err := storefactoryservice.GetStore[*prompttypes.Quota]("promptQuotas")
+	if err != nil {
+		return nil, err
+	}
+
+	templatesStore, err := storefactoryservice.GetStore[*prompttypes.Template]("promptTemplates")
+	if err != nil {
+		return nil, err
+	}
+
 	service := &PromptService{
+		configService:   cs,
 		userService:     userService,
 		modelService:    modelService,
 		appService:      appService,
 		firehoseService: firehoseService,
+		toolService:     toolService,
+		documentService: documentService,
 
 		StreamManager: NewStreamManager(),
+		tokenCounts:   newTokenCountCache(),
+
+		promptsStore:   promptsStore,
+		usageStore:     usageStore,
+		quotasStore:    quotasStore,
+		templatesStore: templatesStore,
 
-		promptsStore: promptsStore,
+		trigger:        make(chan bool, 1),
+		runningPrompts: map[string]bool{},
 
-		trigger: make(chan bool, 1),
+		cancelFuncs: map[string]context.CancelFunc{},
 	}
 
 	prompts, err := service.promptsStore.Query(
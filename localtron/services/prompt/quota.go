@@ -0,0 +1,212 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+	usertypes "github.com/singulatron/singulatron/localtron/services/user/types"
+)
+
+// Limits of a quota, see QuotaExceededError
+const (
+	QuotaLimitPromptsPerHour = "promptsPerHour"
+	QuotaLimitTokensPerDay   = "tokensPerDay"
+	QuotaLimitMaxQueued      = "maxQueued"
+)
+
+// QuotaExceededError is returned by AddPrompt when a user ran out of a quota
+type QuotaExceededError struct {
+	// Limit is the limit of the quota that got exceeded, eg. QuotaLimitPromptsPerHour
+	Limit string
+	Max   int
+	// RetryAfter is when the user can try again,
+	// zero if it depends on other prompts finishing
+	RetryAfter time.Duration
+}
+
+func (e *QuotaExceededError) Error() string {
+	switch e.Limit {
+	case QuotaLimitPromptsPerHour:
+		return fmt.Sprintf("quota exceeded: at most %v prompts per hour", e.Max)
+	case QuotaLimitTokensPerDay:
+		return fmt.Sprintf("quota exceeded: at most %v tokens per day", e.Max)
+	case QuotaLimitMaxQueued:
+		return fmt.Sprintf("quota exceeded: at most %v queued prompts", e.Max)
+	}
+	return "quota exceeded: " + e.Limit
+}
+
+// checkQuota returns a QuotaExceededError if a user can't add more prompts at the moment
+func (p *PromptService) checkQuota(userId string) error {
+	user, found, err := p.userService.GetUser(userId)
+	if err != nil {
+		return errors.Wrap(err, "error getting user")
+	}
+	if !found {
+		return nil
+	}
+
+	quotas, err := p.ListQuotas()
+	if err != nil {
+		return errors.Wrap(err, "error listing quotas")
+	}
+
+	quota := effectiveQuota(quotas, user)
+	if quota == nil {
+		return nil
+	}
+
+	prompts, err := p.promptsStore.Query(
+		datastore.Equal("userId", userId),
+	).Find()
+	if err != nil {
+		return err
+	}
+
+	stats, err := p.usageStore.Query(
+		datastore.Equal("userId", userId),
+	).Find()
+	if err != nil {
+		return err
+	}
+
+	return exceededQuota(quota, prompts, stats, timeNow())
+}
+
+/*
+effectiveQuota returns the quota applying to a user.
+A quota of the user itself takes precedence over the quotas of its roles.
+Out of the quotas of the roles the most generous limit applies.
+Returns nil if no quota applies.
+*/
+func effectiveQuota(quotas []*prompttypes.Quota, user *usertypes.User) *prompttypes.Quota {
+	roles := map[string]bool{}
+	for _, roleId := range user.RoleIds {
+		roles[roleId] = true
+	}
+
+	var ret *prompttypes.Quota
+	for _, quota := range quotas {
+		if quota.UserId != "" && quota.UserId == user.Id {
+			return quota
+		}
+		if quota.RoleId == "" || !roles[quota.RoleId] {
+			continue
+		}
+
+		if ret == nil {
+			ret = &prompttypes.Quota{
+				PromptsPerHour: quota.PromptsPerHour,
+				TokensPerDay:   quota.TokensPerDay,
+				MaxQueued:      quota.MaxQueued,
+			}
+			continue
+		}
+		ret.PromptsPerHour = moreGenerous(ret.PromptsPerHour, quota.PromptsPerHour)
+		ret.TokensPerDay = moreGenerous(ret.TokensPerDay, quota.TokensPerDay)
+		ret.MaxQueued = moreGenerous(ret.MaxQueued, quota.MaxQueued)
+	}
+
+	return ret
+}
+
+// moreGenerous returns the higher of two limits, zero being unlimited
+func moreGenerous(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// exceededQuota checks the prompts and usage stats of a user against a quota
+func exceededQuota(
+	quota *prompttypes.Quota,
+	prompts []*prompttypes.Prompt,
+	stats []*prompttypes.UsageStat,
+	now time.Time,
+) error {
+	if quota.MaxQueued > 0 {
+		queued := 0
+		for _, prompt := range prompts {
+			if !isFinished(prompt) {
+				queued++
+			}
+		}
+		if queued >= quota.MaxQueued {
+			return &QuotaExceededError{
+				Limit: QuotaLimitMaxQueued,
+				Max:   quota.MaxQueued,
+			}
+		}
+	}
+
+	if quota.PromptsPerHour > 0 {
+		hourAgo := now.Add(-time.Hour)
+		count := 0
+		var oldest time.Time
+		for _, prompt := range prompts {
+			at := queuedAt(prompt)
+			if !at.After(hourAgo) {
+				continue
+			}
+			count++
+			if oldest.IsZero() || at.Before(oldest) {
+				oldest = at
+			}
+		}
+		if count >= quota.PromptsPerHour {
+			return &QuotaExceededError{
+				Limit:      QuotaLimitPromptsPerHour,
+				Max:        quota.PromptsPerHour,
+				RetryAfter: oldest.Add(time.Hour).Sub(now),
+			}
+		}
+	}
+
+	if quota.TokensPerDay > 0 {
+		today := now.UTC().Format(prompttypes.UsageStatDayFormat)
+		tokens := 0
+		for _, stat := range stats {
+			if stat.Day == today {
+				tokens += stat.PromptTokens + stat.CompletionTokens
+			}
+		}
+		if tokens >= quota.TokensPerDay {
+			y, m, d := now.UTC().Date()
+			tomorrow := time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+			return &QuotaExceededError{
+				Limit:      QuotaLimitTokensPerDay,
+				Max:        quota.TokensPerDay,
+				RetryAfter: tomorrow.Sub(now),
+			}
+		}
+	}
+
+	return nil
+}
+
+// queuedAt is when a prompt was last queued, retried prompts count as new ones
+func queuedAt(prompt *prompttypes.Prompt) time.Time {
+	if prompt.UpdatedAt.After(prompt.CreatedAt) {
+		return prompt.UpdatedAt
+	}
+	return prompt.CreatedAt
+}
@@ -0,0 +1,119 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+	usertypes "github.com/singulatron/singulatron/localtron/services/user/types"
+)
+
+func TestEffectiveQuota(t *testing.T) {
+	user := &usertypes.User{Id: "u1", RoleIds: []string{"r1", "r2"}}
+
+	roleQuotas := []*prompttypes.Quota{
+		{Id: "q1", RoleId: "r1", PromptsPerHour: 10, TokensPerDay: 1000, MaxQueued: 2},
+		{Id: "q2", RoleId: "r2", PromptsPerHour: 20, TokensPerDay: 0, MaxQueued: 1},
+		{Id: "q3", RoleId: "r3", PromptsPerHour: 1},
+	}
+
+	assert.Nil(t, effectiveQuota(roleQuotas[2:], user))
+
+	quota := effectiveQuota(roleQuotas, user)
+	require.NotNil(t, quota)
+	assert.Equal(t, 20, quota.PromptsPerHour)
+	assert.Equal(t, 0, quota.TokensPerDay)
+	assert.Equal(t, 2, quota.MaxQueued)
+
+	userQuota := &prompttypes.Quota{Id: "q4", UserId: "u1", PromptsPerHour: 3}
+	assert.Equal(t, userQuota, effectiveQuota(append(roleQuotas, userQuota), user))
+}
+
+func TestExceededQuota(t *testing.T) {
+	now := time.Date(2024, 5, 1, 22, 0, 0, 0, time.UTC)
+
+	prompts := []*prompttypes.Prompt{
+		{CreatedAt: now.Add(-2 * time.Hour), Status: prompttypes.PromptStatusCompleted},
+		{CreatedAt: now.Add(-30 * time.Minute), Status: prompttypes.PromptStatusCompleted},
+		{CreatedAt: now.Add(-10 * time.Minute), Status: prompttypes.PromptStatusScheduled},
+	}
+	stats := []*prompttypes.UsageStat{
+		{Day: "2024-04-30", PromptTokens: 500, CompletionTokens: 500},
+		{Day: "2024-05-01", PromptTokens: 50, CompletionTokens: 50},
+	}
+
+	tests := []struct {
+		name       string
+		quota      *prompttypes.Quota
+		limit      string
+		retryAfter time.Duration
+	}{
+		{
+			name:  "Within limits",
+			quota: &prompttypes.Quota{PromptsPerHour: 3, TokensPerDay: 101, MaxQueued: 2},
+		},
+		{
+			name:  "Too many queued",
+			quota: &prompttypes.Quota{MaxQueued: 1},
+			limit: QuotaLimitMaxQueued,
+		},
+		{
+			name:       "Too many prompts in the last hour",
+			quota:      &prompttypes.Quota{PromptsPerHour: 2},
+			limit:      QuotaLimitPromptsPerHour,
+			retryAfter: 30 * time.Minute,
+		},
+		{
+			name:       "Out of tokens for the day",
+			quota:      &prompttypes.Quota{TokensPerDay: 100},
+			limit:      QuotaLimitTokensPerDay,
+			retryAfter: 2 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := exceededQuota(tt.quota, prompts, stats, now)
+			if tt.limit == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			quotaErr, ok := err.(*QuotaExceededError)
+			require.True(t, ok)
+			assert.Equal(t, tt.limit, quotaErr.Limit)
+			assert.Equal(t, tt.retryAfter, quotaErr.RetryAfter)
+		})
+	}
+}
+
+func TestExceededQuotaRetried(t *testing.T) {
+	now := time.Date(2024, 5, 1, 22, 0, 0, 0, time.UTC)
+
+	prompts := []*prompttypes.Prompt{
+		{
+			CreatedAt: now.Add(-2 * time.Hour),
+			UpdatedAt: now.Add(-5 * time.Minute),
+			Status:    prompttypes.PromptStatusCompleted,
+		},
+	}
+
+	err := exceededQuota(&prompttypes.Quota{PromptsPerHour: 1}, prompts, nil, now)
+	quotaErr, ok := err.(*QuotaExceededError)
+	require.True(t, ok)
+	assert.Equal(t, QuotaLimitPromptsPerHour, quotaErr.Limit)
+	assert.Equal(t, 55*time.Minute, quotaErr.RetryAfter)
+}
@@ -17,7 +17,10 @@ import (
 )
 
 func (p *PromptService) registerPermissions() error {
-	for _, permission := range prompttypes.PromptPermissions {
+	for _, permission := range append(
+		prompttypes.PromptPermissions,
+		prompttypes.PromptAdminPermissions...,
+	) {
 		_, err := p.userService.UpsertPermission(
 			permission.Id,
 			permission.Name,
@@ -37,5 +40,9 @@ func (p *PromptService) registerPermissions() error {
 		}
 	}
 
+	for _, permission := range prompttypes.PromptAdminPermissions {
+		p.userService.AddPermissionToRole(usertypes.RoleAdmin.Id, permission.Id)
+	}
+
 	return nil
 }
@@ -0,0 +1,53 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+// retrievalLimit is the number of document passages given to the model
+const retrievalLimit = 4
+
+/*
+retrieve returns the passages of the documents in the collections
+of the thread of a prompt most relevant to the prompt.
+*/
+func (p *PromptService) retrieve(currentPrompt *prompttypes.Prompt) ([]*chattypes.Citation, error) {
+	thread, found, err := p.appService.GetThread(currentPrompt.ThreadId)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting thread")
+	}
+	if !found || len(thread.CollectionIds) == 0 {
+		return nil, nil
+	}
+
+	return p.documentService.Retrieve(currentPrompt.UserId, thread.CollectionIds, currentPrompt.Prompt, retrievalLimit)
+}
+
+// citationInstructions lists the retrieved passages so the model can cite them as [n]
+func citationInstructions(citations []*chattypes.Citation) string {
+	var sb strings.Builder
+	sb.WriteString("Answer using the following document excerpts where they are relevant. ")
+	sb.WriteString("Cite the excerpts you use by their number in square brackets, eg. [1].\n")
+
+	for _, citation := range citations {
+		sb.WriteString(fmt.Sprintf("\n[%d] %v:\n%v\n", citation.Index, citation.DocumentName, citation.Text))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,29 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+)
+
+func TestCitationInstructions(t *testing.T) {
+	instructions := citationInstructions([]*chattypes.Citation{
+		{Index: 1, DocumentName: "deploy.md", Text: "Run make deploy."},
+		{Index: 2, DocumentName: "rollback.md", Text: "Run make rollback."},
+	})
+
+	assert.Contains(t, instructions, "\n[1] deploy.md:\nRun make deploy.\n")
+	assert.Contains(t, instructions, "\n[2] rollback.md:\nRun make rollback.\n")
+}
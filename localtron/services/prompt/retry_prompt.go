@@ -0,0 +1,73 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	"github.com/singulatron/singulatron/localtron/logger"
+
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+var ErrPromptNotAbandoned = errors.New("only abandoned prompts can be retried")
+
+/*
+Retry puts an abandoned prompt of a user back to the queue.
+Returns a QuotaExceededError like AddPrompt if the user ran out of a quota.
+*/
+func (p *PromptService) Retry(userId string, promptId string) error {
+	p.quotaMutex.Lock()
+	defer p.quotaMutex.Unlock()
+
+	prompt, found, err := p.promptsStore.Query(
+		datastore.Id(promptId),
+	).FindOne()
+	if err != nil {
+		return err
+	}
+	if !found || prompt.UserId != userId {
+		return ErrPromptNotFound
+	}
+	if prompt.Status != prompttypes.PromptStatusAbandoned {
+		return ErrPromptNotAbandoned
+	}
+
+	err = p.checkQuota(userId)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Requeueing abandoned prompt",
+		slog.String("promptId", promptId),
+	)
+
+	prompt.Status = prompttypes.PromptStatusScheduled
+	prompt.RunCount = 0
+	prompt.Error = ""
+	prompt.UpdatedAt = timeNow()
+
+	err = p.promptsStore.Query(
+		datastore.Id(promptId),
+	).Update(prompt)
+	if err != nil {
+		return err
+	}
+
+	p.firehoseService.Publish(prompttypes.EventPromptAdded{
+		PromptId: prompt.Id,
+	})
+
+	go p.triggerPromptProcessing()
+	return nil
+}
@@ -0,0 +1,36 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"github.com/google/uuid"
+
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+// SaveQuota creates or updates a quota
+func (p *PromptService) SaveQuota(quota *prompttypes.Quota) (*prompttypes.Quota, error) {
+	err := quota.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if quota.Id == "" {
+		quota.Id = uuid.New().String()
+	}
+
+	err = p.quotasStore.Upsert(quota)
+	if err != nil {
+		return nil, err
+	}
+
+	return quota, nil
+}
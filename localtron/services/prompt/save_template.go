@@ -0,0 +1,65 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+	usertypes "github.com/singulatron/singulatron/localtron/services/user/types"
+)
+
+/*
+SaveTemplate creates or updates a template.
+Templates can only be changed by their owner or an admin.
+*/
+func (p *PromptService) SaveTemplate(user *usertypes.User, tpl *prompttypes.Template) (*prompttypes.Template, error) {
+	err := validateTemplate(tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	now := timeNow()
+	tpl.UpdatedAt = now
+
+	if tpl.Id == "" {
+		tpl.Id = uuid.New().String()
+	}
+
+	existing, found, err := p.templatesStore.Query(
+		datastore.Id(tpl.Id),
+	).FindOne()
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		if !canEditTemplate(user, existing) {
+			return nil, ErrUnauthorized
+		}
+		tpl.CreatedAt = existing.CreatedAt
+		tpl.UserId = existing.UserId
+	} else {
+		tpl.CreatedAt = now
+		tpl.UserId = user.Id
+	}
+
+	err = p.templatesStore.Upsert(tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	return tpl, nil
+}
+
+func canEditTemplate(user *usertypes.User, tpl *prompttypes.Template) bool {
+	return tpl.UserId == user.Id || user.HasRole(usertypes.RoleAdmin.Id)
+}
@@ -0,0 +1,213 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+parseStructuredAnswer parses an answer as JSON
+and validates it against the response schema of the prompt.
+*/
+func parseStructuredAnswer(text string, schema map[string]any) (any, error) {
+	var value any
+	err := json.Unmarshal([]byte(strings.TrimSpace(text)), &value)
+	if err != nil {
+		return nil, fmt.Errorf("answer is not valid JSON: %v", err)
+	}
+
+	err = validateSchema(value, schema, "$")
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+/*
+validateSchema checks a decoded JSON value against a JSON schema.
+Supports the type, enum, const, anyOf, oneOf, properties, required,
+additionalProperties, items, minItems, maxItems, minLength, maxLength,
+minimum and maximum keywords.
+*/
+func validateSchema(value any, schema map[string]any, path string) error {
+	if expected, ok := schema["const"]; ok && !reflect.DeepEqual(normalize(expected), value) {
+		return fmt.Errorf("%v: must be %v", path, expected)
+	}
+
+	if values, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, v := range values {
+			if reflect.DeepEqual(normalize(v), value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%v: must be one of %v", path, values)
+		}
+	}
+
+	if options, ok := schema["anyOf"].([]any); ok && matching(value, options, path) == 0 {
+		return fmt.Errorf("%v: must match a schema of anyOf", path)
+	}
+	if options, ok := schema["oneOf"].([]any); ok && matching(value, options, path) != 1 {
+		return fmt.Errorf("%v: must match exactly one schema of oneOf", path)
+	}
+
+	if typ, ok := schema["type"]; ok {
+		types := []any{typ}
+		if list, ok := typ.([]any); ok {
+			types = list
+		}
+		found := false
+		for _, t := range types {
+			if name, ok := t.(string); ok && hasType(value, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%v: must be of type %v", path, typ)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return validateObject(v, schema, path)
+	case []any:
+		return validateArray(v, schema, path)
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if min, ok := schema["minLength"].(float64); ok && length < min {
+			return fmt.Errorf("%v: must be at least %v characters long", path, min)
+		}
+		if max, ok := schema["maxLength"].(float64); ok && length > max {
+			return fmt.Errorf("%v: must be at most %v characters long", path, max)
+		}
+	case float64:
+		if min, ok := schema["minimum"].(float64); ok && v < min {
+			return fmt.Errorf("%v: must be at least %v", path, min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && v > max {
+			return fmt.Errorf("%v: must be at most %v", path, max)
+		}
+	}
+
+	return nil
+}
+
+func validateObject(object map[string]any, schema map[string]any, path string) error {
+	properties, _ := schema["properties"].(map[string]any)
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%v: missing required property '%v'", path, name)
+			}
+		}
+	}
+
+	for name, v := range object {
+		propertySchema, ok := properties[name].(map[string]any)
+		if !ok {
+			if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+				return fmt.Errorf("%v: unexpected property '%v'", path, name)
+			}
+			continue
+		}
+		err := validateSchema(v, propertySchema, path+"."+name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateArray(array []any, schema map[string]any, path string) error {
+	length := float64(len(array))
+	if min, ok := schema["minItems"].(float64); ok && length < min {
+		return fmt.Errorf("%v: must have at least %v items", path, min)
+	}
+	if max, ok := schema["maxItems"].(float64); ok && length > max {
+		return fmt.Errorf("%v: must have at most %v items", path, max)
+	}
+
+	items, ok := schema["items"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	for i, v := range array {
+		err := validateSchema(v, items, fmt.Sprintf("%v[%v]", path, i))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matching counts the schemas a value matches
+func matching(value any, schemas []any, path string) int {
+	count := 0
+	for _, s := range schemas {
+		schema, ok := s.(map[string]any)
+		if ok && validateSchema(value, schema, path) == nil {
+			count++
+		}
+	}
+	return count
+}
+
+func hasType(value any, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+// normalize converts a schema value to the form json.Unmarshal decodes it into
+func normalize(value any) any {
+	bs, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var ret any
+	json.Unmarshal(bs, &ret)
+	return ret
+}
@@ -0,0 +1,60 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStructuredAnswer(t *testing.T) {
+	schema := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"age": {"type": "integer", "minimum": 0},
+			"tags": {"type": "array", "items": {"enum": ["a", "b"]}, "maxItems": 2}
+		},
+		"required": ["name", "age"],
+		"additionalProperties": false
+	}`), &schema))
+
+	tests := []struct {
+		name   string
+		answer string
+		valid  bool
+	}{
+		{name: "Valid", answer: ` {"name": "Joe", "age": 3, "tags": ["a"]}`, valid: true},
+		{name: "Not JSON", answer: `Sure! {"name": "Joe"}`},
+		{name: "Missing required", answer: `{"name": "Joe"}`},
+		{name: "Wrong type", answer: `{"name": "Joe", "age": 3.5}`},
+		{name: "Below minimum", answer: `{"name": "Joe", "age": -1}`},
+		{name: "Not in enum", answer: `{"name": "Joe", "age": 3, "tags": ["c"]}`},
+		{name: "Too many items", answer: `{"name": "Joe", "age": 3, "tags": ["a", "b", "a"]}`},
+		{name: "Additional property", answer: `{"name": "Joe", "age": 3, "x": 1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parseStructuredAnswer(tt.answer, schema)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Joe", value.(map[string]any)["name"])
+		})
+	}
+}
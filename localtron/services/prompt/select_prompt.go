@@ -12,43 +12,202 @@ package promptservice
 
 import (
 	"math"
+	"sort"
 	"time"
 
-	"github.com/singulatron/singulatron/localtron/datastore"
-
 	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
 )
 
 var timeNow = time.Now
 
-func selectPrompt(promptsMem datastore.DataStore[*prompttypes.Prompt]) (*prompttypes.Prompt, error) {
-	prompts, err := promptsMem.Query(
-		datastore.All(),
-	).OrderBy("createdAt", false).Find()
-	if err != nil {
-		return nil, err
+/*
+selectPrompts picks the prompts that can be started now.
+Prompts are expected to be ordered by creation time.
+
+  - A model processes at most concurrencyPerModel prompts at a time,
+    prompts of the same model are started in queue order, see queueOrder.
+  - Prompts of a thread are processed one after the other so answers
+    arrive in the order of the questions.
+
+Running prompts (the ids in `running` and the ones with a running status)
+count against the concurrency of their model and block their thread.
+*/
+func selectPrompts(
+	prompts []*prompttypes.Prompt,
+	running map[string]bool,
+	concurrencyPerModel int,
+) []*prompttypes.Prompt {
+	if concurrencyPerModel < 1 {
+		concurrencyPerModel = 1
+	}
+
+	modelLoad := map[string]int{}
+	for _, prompt := range prompts {
+		if isRunning(prompt, running) {
+			modelLoad[prompt.ModelId]++
+		}
+	}
+
+	blocked := blockedByThread(prompts, running)
+
+	selected := []*prompttypes.Prompt{}
+	for _, prompt := range queueOrder(prompts, running) {
+		if blocked[prompt.Id] ||
+			modelLoad[prompt.ModelId] >= concurrencyPerModel ||
+			!isDue(prompt) {
+			continue
+		}
+
+		modelLoad[prompt.ModelId]++
+		selected = append(selected, prompt)
+	}
+
+	return selected
+}
+
+/*
+queueOrder returns the waiting prompts in the order they are started.
+Prompts with a higher priority go first. Within a priority users take
+turns: the queue holds the first prompt of every user, then the second
+prompt of every user and so on, so a user submitting many prompts
+does not starve the others. Users with fewer running prompts
+and older prompts go first in every turn.
+Prompts are expected to be ordered by creation time.
+*/
+func queueOrder(prompts []*prompttypes.Prompt, running map[string]bool) []*prompttypes.Prompt {
+	runningPerUser := map[string]int{}
+	for _, prompt := range prompts {
+		if isRunning(prompt, running) {
+			runningPerUser[prompt.UserId]++
+		}
 	}
 
+	priorities := []int{}
+	userQueues := map[int]map[string][]*prompttypes.Prompt{}
 	for _, prompt := range prompts {
-		if prompt.Status == prompttypes.PromptStatusAbandoned ||
-			prompt.Status == prompttypes.PromptStatusCompleted ||
-			prompt.Status == prompttypes.PromptStatusCanceled {
+		if isRunning(prompt, running) || isFinished(prompt) {
 			continue
 		}
 
-		runCount := prompt.RunCount
-		if prompt.RunCount == 0 {
-			// otherwise backoff is 0s
-			runCount = 1
+		if _, ok := userQueues[prompt.Priority]; !ok {
+			userQueues[prompt.Priority] = map[string][]*prompttypes.Prompt{}
+			priorities = append(priorities, prompt.Priority)
+		}
+		userQueues[prompt.Priority][prompt.UserId] = append(
+			userQueues[prompt.Priority][prompt.UserId],
+			prompt,
+		)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+
+	ret := []*prompttypes.Prompt{}
+	for _, priority := range priorities {
+		queues := userQueues[priority]
+
+		userIds := []string{}
+		for userId := range queues {
+			userIds = append(userIds, userId)
 		}
-		cappedRunCount := math.Min(float64(runCount), 10)
-		backoff := baseDelay * time.Duration(math.Pow(2, cappedRunCount-1))
+		sort.Slice(userIds, func(i, j int) bool {
+			a, b := userIds[i], userIds[j]
+			if runningPerUser[a] != runningPerUser[b] {
+				return runningPerUser[a] < runningPerUser[b]
+			}
+			if !queues[a][0].CreatedAt.Equal(queues[b][0].CreatedAt) {
+				return queues[a][0].CreatedAt.Before(queues[b][0].CreatedAt)
+			}
+			return a < b
+		})
 
-		if prompt.RunCount == 0 ||
-			timeNow().Sub(prompt.LastRun) >= backoff {
-			return prompt, nil
+		for turn := 0; ; turn++ {
+			added := false
+			for _, userId := range userIds {
+				if turn < len(queues[userId]) {
+					ret = append(ret, queues[userId][turn])
+					added = true
+				}
+			}
+			if !added {
+				break
+			}
 		}
 	}
 
-	return nil, nil
+	return ret
+}
+
+/*
+queuePositions tells the place of every waiting prompt in the queue
+of its model, starting from 1.
+*/
+func queuePositions(prompts []*prompttypes.Prompt, running map[string]bool) map[string]int {
+	positions := map[string]int{}
+	modelCounts := map[string]int{}
+
+	for _, prompt := range queueOrder(prompts, running) {
+		modelCounts[prompt.ModelId]++
+		positions[prompt.Id] = modelCounts[prompt.ModelId]
+	}
+
+	return positions
+}
+
+/*
+blockedByThread returns the ids of the waiting prompts
+which have an unfinished prompt created before them in the same thread.
+*/
+func blockedByThread(prompts []*prompttypes.Prompt, running map[string]bool) map[string]bool {
+	blocked := map[string]bool{}
+	busyThreads := map[string]bool{}
+
+	for _, prompt := range prompts {
+		if isRunning(prompt, running) {
+			busyThreads[threadKey(prompt)] = true
+		}
+	}
+
+	for _, prompt := range prompts {
+		if isRunning(prompt, running) || isFinished(prompt) {
+			continue
+		}
+
+		if busyThreads[threadKey(prompt)] {
+			blocked[prompt.Id] = true
+		}
+		busyThreads[threadKey(prompt)] = true
+	}
+
+	return blocked
+}
+
+// running is checked first: the status of the prompts in it is changed by their workers
+func isRunning(prompt *prompttypes.Prompt, running map[string]bool) bool {
+	return running[prompt.Id] || prompt.Status == prompttypes.PromptStatusRunning
+}
+
+// prompts without a thread are their own thread
+func threadKey(prompt *prompttypes.Prompt) string {
+	if prompt.ThreadId == "" {
+		return prompt.Id
+	}
+
+	return prompt.ThreadId
+}
+
+func isFinished(prompt *prompttypes.Prompt) bool {
+	return prompt.Status == prompttypes.PromptStatusAbandoned ||
+		prompt.Status == prompttypes.PromptStatusCompleted ||
+		prompt.Status == prompttypes.PromptStatusCanceled
+}
+
+// isDue tells if the retry backoff of a prompt has passed
+func isDue(prompt *prompttypes.Prompt) bool {
+	if prompt.RunCount == 0 {
+		return true
+	}
+
+	cappedRunCount := math.Min(float64(prompt.RunCount), 10)
+	backoff := baseDelay * time.Duration(math.Pow(2, cappedRunCount-1))
+
+	return timeNow().Sub(prompt.LastRun) >= backoff
 }
@@ -14,6 +14,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/singulatron/singulatron/localtron/datastore"
 	"github.com/singulatron/singulatron/localtron/datastore/localstore"
 
 	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
@@ -106,9 +107,160 @@ func TestSelectPrompt(t *testing.T) {
 			memStore := localstore.NewLocalStore[*prompttypes.Prompt]("")
 			err := memStore.UpsertMany(tt.prompts)
 			assert.NoError(t, err)
-			actualPrompt, err := selectPrompt(memStore)
+			prompts, err := memStore.Query(
+				datastore.All(),
+			).OrderBy("createdAt", false).Find()
 			assert.NoError(t, err)
+
+			var actualPrompt *prompttypes.Prompt
+			selected := selectPrompts(prompts, map[string]bool{}, 1)
+			if len(selected) > 0 {
+				actualPrompt = selected[0]
+			}
 			assert.Equal(t, tt.expectedPrompt, actualPrompt)
 		})
 	}
 }
+
+func TestSelectPrompts(t *testing.T) {
+	fixedTime := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time {
+		return fixedTime
+	}
+
+	ids := func(prompts []*prompttypes.Prompt) []string {
+		ret := []string{}
+		for _, prompt := range prompts {
+			ret = append(ret, prompt.Id)
+		}
+		return ret
+	}
+
+	tests := []struct {
+		name        string
+		prompts     []*prompttypes.Prompt
+		running     map[string]bool
+		concurrency int
+		expectedIds []string
+	}{
+		{
+			name: "One prompt per model",
+			prompts: []*prompttypes.Prompt{
+				{Id: "1", ThreadId: "t1", ModelId: "sd", Status: prompttypes.PromptStatusScheduled},
+				{Id: "2", ThreadId: "t2", ModelId: "sd", Status: prompttypes.PromptStatusScheduled},
+				{Id: "3", ThreadId: "t3", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+			},
+			concurrency: 1,
+			expectedIds: []string{"1", "3"},
+		},
+		{
+			name: "Running prompt occupies its model",
+			prompts: []*prompttypes.Prompt{
+				{Id: "1", ThreadId: "t1", ModelId: "sd", Status: prompttypes.PromptStatusRunning},
+				{Id: "2", ThreadId: "t2", ModelId: "sd", Status: prompttypes.PromptStatusScheduled},
+				{Id: "3", ThreadId: "t3", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+			},
+			running:     map[string]bool{"1": true},
+			concurrency: 1,
+			expectedIds: []string{"3"},
+		},
+		{
+			name: "Multiple prompts per model",
+			prompts: []*prompttypes.Prompt{
+				{Id: "1", ThreadId: "t1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+				{Id: "2", ThreadId: "t2", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+				{Id: "3", ThreadId: "t3", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+			},
+			concurrency: 2,
+			expectedIds: []string{"1", "2"},
+		},
+		{
+			name: "Prompts of a thread wait for each other",
+			prompts: []*prompttypes.Prompt{
+				{Id: "1", ThreadId: "t1", ModelId: "sd", Status: prompttypes.PromptStatusScheduled},
+				{Id: "2", ThreadId: "t1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+				{Id: "3", ThreadId: "t2", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+			},
+			concurrency: 1,
+			expectedIds: []string{"1", "3"},
+		},
+		{
+			name: "Prompt waiting for a retry blocks its thread only",
+			prompts: []*prompttypes.Prompt{
+				{Id: "1", ThreadId: "t1", ModelId: "mistral", Status: prompttypes.PromptStatusErrored, RunCount: 1, LastRun: fixedTime},
+				{Id: "2", ThreadId: "t1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+				{Id: "3", ThreadId: "t2", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+			},
+			concurrency: 1,
+			expectedIds: []string{"3"},
+		},
+		{
+			name: "Higher priority goes first",
+			prompts: []*prompttypes.Prompt{
+				{Id: "1", ThreadId: "t1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+				{Id: "2", ThreadId: "t2", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled, Priority: prompttypes.PromptPriorityHigh},
+			},
+			concurrency: 1,
+			expectedIds: []string{"2"},
+		},
+		{
+			name: "Users take turns",
+			prompts: []*prompttypes.Prompt{
+				{Id: "1", ThreadId: "t1", UserId: "u1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+				{Id: "2", ThreadId: "t2", UserId: "u1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+				{Id: "3", ThreadId: "t3", UserId: "u1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+				{Id: "4", ThreadId: "t4", UserId: "u2", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+			},
+			concurrency: 2,
+			expectedIds: []string{"1", "4"},
+		},
+		{
+			name: "User with a running prompt waits",
+			prompts: []*prompttypes.Prompt{
+				{Id: "1", ThreadId: "t1", UserId: "u1", ModelId: "mistral", Status: prompttypes.PromptStatusRunning},
+				{Id: "2", ThreadId: "t2", UserId: "u1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+				{Id: "3", ThreadId: "t3", UserId: "u2", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+			},
+			concurrency: 2,
+			expectedIds: []string{"3"},
+		},
+		{
+			name: "Priority does not reorder a thread",
+			prompts: []*prompttypes.Prompt{
+				{Id: "1", ThreadId: "t1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+				{Id: "2", ThreadId: "t1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled, Priority: prompttypes.PromptPriorityHigh},
+			},
+			concurrency: 1,
+			expectedIds: []string{"1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			running := tt.running
+			if running == nil {
+				running = map[string]bool{}
+			}
+			selected := selectPrompts(tt.prompts, running, tt.concurrency)
+			assert.Equal(t, tt.expectedIds, ids(selected))
+		})
+	}
+}
+
+func TestQueuePositions(t *testing.T) {
+	prompts := []*prompttypes.Prompt{
+		{Id: "1", UserId: "u1", ModelId: "mistral", Status: prompttypes.PromptStatusRunning},
+		{Id: "2", UserId: "u1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+		{Id: "3", UserId: "u1", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+		{Id: "4", UserId: "u2", ModelId: "mistral", Status: prompttypes.PromptStatusScheduled},
+		{Id: "5", UserId: "u2", ModelId: "sd", Status: prompttypes.PromptStatusScheduled},
+		{Id: "6", UserId: "u2", ModelId: "sd", Status: prompttypes.PromptStatusCompleted},
+	}
+
+	assert.Equal(t, map[string]int{
+		"4": 1,
+		"2": 2,
+		"3": 3,
+		"5": 1,
+	}, queuePositions(prompts, map[string]bool{}))
+}
@@ -12,40 +12,80 @@ package promptservice
 
 import (
 	"sync"
+	"time"
 
 	"github.com/singulatron/singulatron/localtron/clients/llm"
 )
 
-type SubscriberChan chan *llm.CompletionResponse
+// finished answers are kept this long for reconnecting clients
+const streamRetention = 1 * time.Minute
+
+/*
+StreamEvent is a streamed response with its sequence number.
+Sequence numbers increase over all threads and are
+sent to clients as the SSE event id.
+*/
+type StreamEvent struct {
+	Seq      int64
+	Response *llm.CompletionResponse
+}
+
+type SubscriberChan chan *StreamEvent
+
+// answerStream is the streamed responses of a single answer
+type answerStream struct {
+	events     []*StreamEvent
+	finished   bool
+	finishedAt time.Time
+}
 
 type StreamManager struct {
 	streams map[string][]SubscriberChan
-	history map[string][]*llm.CompletionResponse
+	// answers of a thread, the last one is being streamed
+	// unless it is finished
+	answers map[string][]*answerStream
+	lastSeq int64
 	lock    sync.RWMutex
 }
 
 func NewStreamManager() *StreamManager {
 	return &StreamManager{
 		streams: make(map[string][]SubscriberChan),
-		history: make(map[string][]*llm.CompletionResponse),
+		answers: make(map[string][]*answerStream),
+		// starting from the current time keeps event ids increasing
+		// over restarts so stale ids of clients don't hide new events
+		lastSeq: time.Now().UnixMilli(),
 	}
 }
 
-func (sm *StreamManager) Subscribe(threadId string, subscriber SubscriberChan) {
+/*
+Subscribe registers a subscriber for the responses of a thread.
+Returns the responses the subscriber missed: the ones after lastEventId
+if it is set, otherwise the ones of the answer being streamed.
+The missed responses must be sent to the client before the ones
+arriving on the channel.
+*/
+func (sm *StreamManager) Subscribe(threadId string, subscriber SubscriberChan, lastEventId int64) []*StreamEvent {
 	sm.lock.Lock()
 	defer sm.lock.Unlock()
 
-	// Add subscriber to the list
+	sm.prune()
+
 	sm.streams[threadId] = append(sm.streams[threadId], subscriber)
 
-	// Send historical messages to the new subscriber
-	go func() {
-		sm.lock.RLock()
-		defer sm.lock.RUnlock()
-		for _, msg := range sm.history[threadId] {
-			subscriber <- msg
+	missed := []*StreamEvent{}
+	for _, answer := range sm.answers[threadId] {
+		if lastEventId == 0 && answer.finished {
+			continue
 		}
-	}()
+		for _, event := range answer.events {
+			if event.Seq > lastEventId {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	return missed
 }
 
 func (sm *StreamManager) Unsubscribe(threadId string, subscriber SubscriberChan) {
@@ -59,20 +99,103 @@ func (sm *StreamManager) Unsubscribe(threadId string, subscriber SubscriberChan)
 			break
 		}
 	}
+	if len(sm.streams[threadId]) == 0 {
+		delete(sm.streams, threadId)
+	}
+}
+
+// History returns the responses of the answer being streamed to a thread
+func (sm *StreamManager) History(threadId string) []*llm.CompletionResponse {
+	sm.lock.RLock()
+	defer sm.lock.RUnlock()
+
+	answer := sm.currentAnswer(threadId)
+	if answer == nil {
+		return nil
+	}
+
+	ret := []*llm.CompletionResponse{}
+	for _, event := range answer.events {
+		ret = append(ret, event.Response)
+	}
+
+	return ret
+}
+
+/*
+FinishAnswer marks the answer being streamed to a thread as finished,
+once it is saved as a message. Its responses are kept for reconnecting
+clients for the retention period.
+*/
+func (sm *StreamManager) FinishAnswer(threadId string) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	answer := sm.currentAnswer(threadId)
+	if answer == nil {
+		return
+	}
+	answer.finished = true
+	answer.finishedAt = timeNow()
+
+	sm.prune()
 }
 
 func (sm *StreamManager) Broadcast(threadId string, response *llm.CompletionResponse) {
 	sm.lock.Lock()
 	defer sm.lock.Unlock()
+
+	answer := sm.currentAnswer(threadId)
+	if answer == nil {
+		answer = &answerStream{}
+		sm.answers[threadId] = append(sm.answers[threadId], answer)
+	}
+
+	sm.lastSeq++
+	event := &StreamEvent{
+		Seq:      sm.lastSeq,
+		Response: response,
+	}
+	answer.events = append(answer.events, event)
+
 	if subscribers, ok := sm.streams[threadId]; ok {
 		for _, subscriber := range subscribers {
 			select {
-			case subscriber <- response:
+			case subscriber <- event:
 			default:
 				// Handle full channel or unresponsive subscriber
 			}
 		}
 	}
-	// Append the new response to the history
-	sm.history[threadId] = append(sm.history[threadId], response)
+}
+
+// currentAnswer returns the unfinished answer of a thread, must be called with the lock held
+func (sm *StreamManager) currentAnswer(threadId string) *answerStream {
+	answers := sm.answers[threadId]
+	if len(answers) == 0 || answers[len(answers)-1].finished {
+		return nil
+	}
+
+	return answers[len(answers)-1]
+}
+
+// prune drops the answers past their retention, must be called with the lock held
+func (sm *StreamManager) prune() {
+	cutoff := timeNow().Add(-streamRetention)
+
+	for threadId, answers := range sm.answers {
+		kept := []*answerStream{}
+		for _, answer := range answers {
+			if answer.finished && answer.finishedAt.Before(cutoff) {
+				continue
+			}
+			kept = append(kept, answer)
+		}
+
+		if len(kept) == 0 {
+			delete(sm.answers, threadId)
+			continue
+		}
+		sm.answers[threadId] = kept
+	}
 }
@@ -0,0 +1,97 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singulatron/singulatron/localtron/clients/llm"
+)
+
+func response(text string) *llm.CompletionResponse {
+	return &llm.CompletionResponse{
+		Choices: []llm.CompletionChoice{
+			{Text: text},
+		},
+	}
+}
+
+func texts(events []*StreamEvent) []string {
+	ret := []string{}
+	for _, event := range events {
+		ret = append(ret, event.Response.Choices[0].Text)
+	}
+	return ret
+}
+
+func TestStreamManager(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+
+	sm := NewStreamManager()
+
+	sm.Broadcast("t1", response("Hello"))
+	sm.Broadcast("t1", response(" there"))
+
+	t.Run("new subscriber gets the answer being streamed", func(t *testing.T) {
+		sub := make(SubscriberChan, 10)
+		missed := sm.Subscribe("t1", sub, 0)
+		defer sm.Unsubscribe("t1", sub)
+
+		assert.Equal(t, []string{"Hello", " there"}, texts(missed))
+	})
+
+	sub := make(SubscriberChan, 10)
+	missed := sm.Subscribe("t1", sub, 0)
+	require.Equal(t, 2, len(missed))
+	firstSeq := missed[0].Seq
+
+	sm.Broadcast("t1", response("!"))
+	event := <-sub
+	assert.Equal(t, missed[1].Seq+1, event.Seq)
+	sm.Unsubscribe("t1", sub)
+
+	assert.Equal(t, 3, len(sm.History("t1")))
+	sm.FinishAnswer("t1")
+	assert.Equal(t, 0, len(sm.History("t1")))
+
+	t.Run("reconnecting subscriber gets exactly the missed responses", func(t *testing.T) {
+		sub := make(SubscriberChan, 10)
+		missed := sm.Subscribe("t1", sub, firstSeq)
+		defer sm.Unsubscribe("t1", sub)
+
+		assert.Equal(t, []string{" there", "!"}, texts(missed))
+	})
+
+	t.Run("finished answers are not replayed to new subscribers", func(t *testing.T) {
+		sub := make(SubscriberChan, 10)
+		missed := sm.Subscribe("t1", sub, 0)
+		defer sm.Unsubscribe("t1", sub)
+
+		assert.Equal(t, 0, len(missed))
+	})
+
+	t.Run("finished answers expire", func(t *testing.T) {
+		now = now.Add(streamRetention + time.Second)
+
+		sub := make(SubscriberChan, 10)
+		missed := sm.Subscribe("t1", sub, firstSeq)
+		defer sm.Unsubscribe("t1", sub)
+
+		assert.Equal(t, 0, len(missed))
+	})
+}
@@ -0,0 +1,146 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/clients/llm"
+	"github.com/singulatron/singulatron/localtron/logger"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+const (
+	summaryMaxTokens   = 256
+	summaryInstruction = "Summarize the following conversation in a few sentences. Keep every fact that is needed to continue the conversation.\n\n"
+)
+
+// summarize asks the model to summarize a list of turns
+func (p *PromptService) summarize(
+	ctx context.Context,
+	address string,
+	tokenizer Tokenizer,
+	model *modeltypes.Model,
+	turns []turn,
+) (string, error) {
+	lines := []string{}
+	for _, t := range turns {
+		switch t.Role {
+		case chattypes.MessageRoleSystem:
+			lines = append(lines, "Context: "+t.Content)
+		case chattypes.MessageRoleAssistant:
+			lines = append(lines, "Assistant: "+t.Content)
+		default:
+			lines = append(lines, "User: "+t.Content)
+		}
+	}
+	conversation := strings.Join(lines, "\n")
+
+	templates := model.GetRoleTemplates()
+	budget := model.GetContextLength() - summaryMaxTokens
+
+	// if even the summary request is too long we cut the oldest
+	// part of the conversation
+	for {
+		prompt := renderTurns([]turn{{
+			Role:    chattypes.MessageRoleUser,
+			Content: summaryInstruction + conversation,
+		}}, templates)
+
+		count, err := tokenizer.CountTokens(prompt)
+		if err != nil {
+			return "", err
+		}
+		if count <= budget {
+			break
+		}
+
+		runes := []rune(conversation)
+		cut := len(runes) * (count - budget) / count
+		if cut < 1 {
+			cut = 1
+		}
+		if cut >= len(runes) {
+			return "", errors.New("conversation is too long to summarize")
+		}
+		conversation = string(runes[cut:])
+	}
+
+	llmClient := llm.Client{
+		LLMAddress: address,
+	}
+
+	rsp, err := llmClient.PostCompletions(ctx, llm.PostCompletionsRequest{
+		Prompt: renderTurns([]turn{{
+			Role:    chattypes.MessageRoleUser,
+			Content: summaryInstruction + conversation,
+		}}, templates),
+		MaxTokens: summaryMaxTokens,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "error summarizing conversation")
+	}
+	if len(rsp.Choices) == 0 {
+		return "", errors.New("no summary in response")
+	}
+
+	return strings.TrimSpace(rsp.Choices[0].Text), nil
+}
+
+/*
+saveSummary saves a summary as a synthetic message to the thread
+so the summarized messages can be skipped by later prompts.
+*/
+func (p *PromptService) saveSummary(threadId string, messages []*chattypes.Message, built *builtContext) error {
+	summaryOf := []string{}
+	for _, t := range built.Summarized {
+		if t.MessageId != "" {
+			summaryOf = append(summaryOf, t.MessageId)
+		}
+		summaryOf = append(summaryOf, t.SummaryOf...)
+	}
+
+	// place the summary right after the last message it summarizes
+	createdAt := time.Now()
+	parentId := ""
+	if len(built.Summarized) > 0 {
+		lastId := built.Summarized[len(built.Summarized)-1].MessageId
+		for _, message := range messages {
+			if message.Id == lastId {
+				createdAt = message.CreatedAt.Add(time.Millisecond)
+				parentId = lastId
+			}
+		}
+	}
+
+	logger.Info("Summarizing older messages of thread",
+		slog.String("threadId", threadId),
+		slog.Int("summarizedMessages", len(summaryOf)),
+	)
+
+	return p.appService.AddMessage(&chattypes.Message{
+		Id:        uuid.New().String(),
+		ThreadId:  threadId,
+		Role:      chattypes.MessageRoleSystem,
+		Content:   built.Summary.Content,
+		ParentId:  parentId,
+		CreatedAt: createdAt,
+		SummaryOf: summaryOf,
+	})
+}
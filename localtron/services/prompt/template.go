@@ -0,0 +1,147 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	"github.com/singulatron/singulatron/localtron/logger"
+
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+	ErrUnauthorized     = errors.New("unauthorized")
+)
+
+type renderedTemplate struct {
+	SystemPrompt  string
+	RoleTemplates modeltypes.RoleTemplates
+}
+
+/*
+renderTemplate renders the system prompt and the role templates
+of a template. Values missing for a variable are taken from its default,
+values for variables the template doesn't declare are ignored.
+*/
+func renderTemplate(tpl *prompttypes.Template, values map[string]string) (*renderedTemplate, error) {
+	data := map[string]string{}
+	for _, variable := range tpl.Variables {
+		data[variable.Name] = variable.Default
+		if value, ok := values[variable.Name]; ok {
+			data[variable.Name] = value
+		}
+	}
+
+	ret := &renderedTemplate{}
+
+	var err error
+	ret.SystemPrompt, err = execute("system prompt", tpl.SystemPrompt, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if tpl.RoleTemplates == nil {
+		return ret, nil
+	}
+	ret.RoleTemplates.System, err = execute("system template", tpl.RoleTemplates.System, data)
+	if err != nil {
+		return nil, err
+	}
+	ret.RoleTemplates.User, err = execute("user template", tpl.RoleTemplates.User, data)
+	if err != nil {
+		return nil, err
+	}
+	ret.RoleTemplates.Assistant, err = execute("assistant template", tpl.RoleTemplates.Assistant, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func execute(name string, text string, data map[string]string) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	tpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", errors.Wrap(err, "error parsing "+name)
+	}
+
+	var out strings.Builder
+	err = tpl.Execute(&out, data)
+	if err != nil {
+		return "", errors.Wrap(err, "error rendering "+name)
+	}
+
+	return out.String(), nil
+}
+
+// validateTemplate checks that a template renders with the defaults of its variables
+func validateTemplate(tpl *prompttypes.Template) error {
+	if strings.TrimSpace(tpl.Name) == "" {
+		return errors.New("template name is missing")
+	}
+
+	names := map[string]bool{}
+	for _, variable := range tpl.Variables {
+		if variable.Name == "" {
+			return errors.New("template variable name is missing")
+		}
+		if names[variable.Name] {
+			return fmt.Errorf("template variable '%v' is declared twice", variable.Name)
+		}
+		names[variable.Name] = true
+	}
+
+	_, err := renderTemplate(tpl, nil)
+	return err
+}
+
+/*
+threadTemplate renders the template of a thread.
+Returns nil if the thread has no template or its template got deleted.
+*/
+func (p *PromptService) threadTemplate(threadId string) (*renderedTemplate, error) {
+	thread, found, err := p.appService.GetThread(threadId)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting thread")
+	}
+	if !found || thread.TemplateId == "" {
+		return nil, nil
+	}
+
+	tpl, found, err := p.templatesStore.Query(
+		datastore.Id(thread.TemplateId),
+	).FindOne()
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		logger.Warn("Template of thread not found",
+			slog.String("threadId", threadId),
+			slog.String("templateId", thread.TemplateId),
+		)
+		return nil, nil
+	}
+
+	return renderTemplate(tpl, thread.TemplateVariables)
+}
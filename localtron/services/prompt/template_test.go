@@ -0,0 +1,89 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singulatron/singulatron/localtron/datastore/localstore"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+	usertypes "github.com/singulatron/singulatron/localtron/services/user/types"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	tpl := &prompttypes.Template{
+		Name:         "Code reviewer",
+		SystemPrompt: "You review {{.language}} code. Be {{.tone}}.",
+		RoleTemplates: &modeltypes.RoleTemplates{
+			User: "[{{.language}}] {prompt}",
+		},
+		Variables: []prompttypes.TemplateVariable{
+			{Name: "language", Default: "Go"},
+			{Name: "tone"},
+		},
+	}
+	require.NoError(t, validateTemplate(tpl))
+
+	rendered, err := renderTemplate(tpl, map[string]string{
+		"tone":    "kind",
+		"unknown": "ignored",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "You review Go code. Be kind.", rendered.SystemPrompt)
+	assert.Equal(t, "[Go] {prompt}", rendered.RoleTemplates.User)
+	assert.Equal(t, "", rendered.RoleTemplates.System)
+
+	t.Run("undeclared variable", func(t *testing.T) {
+		err := validateTemplate(&prompttypes.Template{
+			Name:         "Broken",
+			SystemPrompt: "You speak {{.language}}.",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("duplicate variable", func(t *testing.T) {
+		err := validateTemplate(&prompttypes.Template{
+			Name: "Broken",
+			Variables: []prompttypes.TemplateVariable{
+				{Name: "language"},
+				{Name: "language"},
+			},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestTemplateOwnership(t *testing.T) {
+	p := &PromptService{
+		templatesStore: localstore.NewLocalStore[*prompttypes.Template](""),
+	}
+	owner := &usertypes.User{Id: "owner"}
+	other := &usertypes.User{Id: "other"}
+	admin := &usertypes.User{Id: "admin", RoleIds: []string{usertypes.RoleAdmin.Id}}
+
+	tpl, err := p.SaveTemplate(owner, &prompttypes.Template{Name: "Reviewer"})
+	require.NoError(t, err)
+
+	_, err = p.SaveTemplate(other, &prompttypes.Template{Id: tpl.Id, Name: "Taken over"})
+	require.ErrorIs(t, err, ErrUnauthorized)
+	require.ErrorIs(t, p.DeleteTemplate(other, tpl.Id), ErrUnauthorized)
+
+	saved, err := p.SaveTemplate(admin, &prompttypes.Template{Id: tpl.Id, Name: "Renamed"})
+	require.NoError(t, err)
+	require.Equal(t, owner.Id, saved.UserId, "the owner doesn't change")
+
+	require.NoError(t, p.DeleteTemplate(owner, tpl.Id))
+	require.ErrorIs(t, p.DeleteTemplate(owner, tpl.Id), ErrTemplateNotFound)
+}
@@ -0,0 +1,132 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/singulatron/singulatron/localtron/clients/llm"
+	"github.com/singulatron/singulatron/localtron/logger"
+)
+
+// Tokenizer counts the tokens of a text for a given model.
+type Tokenizer interface {
+	CountTokens(text string) (int, error)
+}
+
+/*
+estimatingTokenizer is a local tokenizer for when the model
+cannot be asked. Around 4 characters make a token for
+English text, which errs on the safe side for most models.
+*/
+type estimatingTokenizer struct{}
+
+func (e estimatingTokenizer) CountTokens(text string) (int, error) {
+	return (utf8.RuneCountInString(text) + 3) / 4, nil
+}
+
+// maxCachedTokenCounts bounds the memory of the token count cache
+const maxCachedTokenCounts = 10000
+
+/*
+tokenCountCache remembers the token counts of texts by model,
+so the history of a thread is not tokenized again for every prompt.
+*/
+type tokenCountCache struct {
+	mutex  sync.Mutex
+	counts map[string]int
+}
+
+func newTokenCountCache() *tokenCountCache {
+	return &tokenCountCache{
+		counts: map[string]int{},
+	}
+}
+
+func tokenCountKey(modelId string, text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return modelId + ":" + hex.EncodeToString(sum[:])
+}
+
+func (c *tokenCountCache) get(key string) (int, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	count, ok := c.counts[key]
+	return count, ok
+}
+
+func (c *tokenCountCache) set(key string, count int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.counts) >= maxCachedTokenCounts {
+		c.counts = map[string]int{}
+	}
+	c.counts[key] = count
+}
+
+/*
+llmTokenizer uses the tokenize endpoint of a running llama-cpp
+container. Older containers don't have the endpoint so after
+the first failure it falls back to estimating.
+Counts are cached by model if a cache is given, estimates are not.
+*/
+type llmTokenizer struct {
+	client   *llm.Client
+	modelId  string
+	cache    *tokenCountCache
+	failed   bool
+	fallback estimatingTokenizer
+}
+
+func newLlmTokenizer(address string, modelId string, cache *tokenCountCache) *llmTokenizer {
+	return &llmTokenizer{
+		client: &llm.Client{
+			LLMAddress: address,
+		},
+		modelId: modelId,
+		cache:   cache,
+	}
+}
+
+func (l *llmTokenizer) CountTokens(text string) (int, error) {
+	if l.failed {
+		return l.fallback.CountTokens(text)
+	}
+
+	key := ""
+	if l.cache != nil {
+		key = tokenCountKey(l.modelId, text)
+		if count, ok := l.cache.get(key); ok {
+			return count, nil
+		}
+	}
+
+	tokens, err := l.client.Tokenize(text)
+	if err != nil {
+		logger.Warn("Tokenizing with the model failed, estimating token counts instead",
+			slog.String("error", err.Error()),
+		)
+		l.failed = true
+		return l.fallback.CountTokens(text)
+	}
+
+	if l.cache != nil {
+		l.cache.set(key, len(tokens))
+	}
+
+	return len(tokens), nil
+}
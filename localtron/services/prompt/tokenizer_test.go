@@ -0,0 +1,42 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCountCache(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"tokens": [1, 2, 3]}`))
+	}))
+	defer server.Close()
+
+	cache := newTokenCountCache()
+	for i := 0; i < 3; i++ {
+		// every prompt builds its own tokenizer
+		count, err := newLlmTokenizer(server.URL, "model", cache).CountTokens("hello there")
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	_, err := newLlmTokenizer(server.URL, "other-model", cache).CountTokens("hello there")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests), "counts are cached by model")
+}
@@ -0,0 +1,167 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+	tooltypes "github.com/singulatron/singulatron/localtron/services/tool/types"
+)
+
+const (
+	defaultMaxToolSteps = 5
+	toolCallStart       = "<tool_call>"
+	toolCallEnd         = "</tool_call>"
+	toolResultStart     = "<tool_result>"
+	toolResultEnd       = "</tool_result>"
+)
+
+// toolInstructions tells the model how to call the given tools
+func toolInstructions(tools []*tooltypes.Tool) string {
+	var result strings.Builder
+
+	result.WriteString("You can use the following tools. To call a tool answer with only\n")
+	result.WriteString(toolCallStart + `{"name": "<tool name>", "arguments": {<arguments>}}` + toolCallEnd + "\n")
+	result.WriteString("and you will get its result. Answer normally once you know enough.\n\nTools:\n")
+
+	for _, tool := range tools {
+		result.WriteString(fmt.Sprintf("- %v: %v", tool.Id, tool.Description))
+		if tool.Parameters != nil {
+			bs, _ := json.Marshal(tool.Parameters)
+			result.WriteString(fmt.Sprintf(" Arguments: %s", bs))
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
+
+/*
+parseToolCall looks for a tool call in an answer.
+Returns the text before the call and the call itself.
+*/
+func parseToolCall(text string) (string, *chattypes.ToolCall, bool) {
+	start := strings.Index(text, toolCallStart)
+	if start < 0 {
+		return text, nil, false
+	}
+
+	// the closing tag is a stop sequence so it is usually missing
+	body := text[start+len(toolCallStart):]
+	if end := strings.Index(body, toolCallEnd); end >= 0 {
+		body = body[:end]
+	}
+
+	call := struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	}{}
+	err := json.Unmarshal([]byte(strings.TrimSpace(body)), &call)
+	if err != nil || call.Name == "" {
+		return text, nil, false
+	}
+
+	return text[:start], &chattypes.ToolCall{
+		Id:        uuid.New().String(),
+		Name:      call.Name,
+		Arguments: call.Arguments,
+	}, true
+}
+
+// toolCallTurn renders an answer calling a tool the way the model made it
+func toolCallTurn(content string, call *chattypes.ToolCall) string {
+	bs, _ := json.Marshal(map[string]any{
+		"name":      call.Name,
+		"arguments": call.Arguments,
+	})
+
+	return content + toolCallStart + string(bs) + toolCallEnd
+}
+
+func toolResultTurn(content string) string {
+	return toolResultStart + content + toolResultEnd
+}
+
+/*
+runToolCall runs a tool called by the model and saves its result
+as a tool message replying to the message of the call.
+Errors of the tool are saved as the result so the model can recover from them.
+Returns the id of the saved result.
+*/
+func (p *PromptService) runToolCall(
+	ctx context.Context,
+	currentPrompt *prompttypes.Prompt,
+	parentId string,
+	tools []*tooltypes.Tool,
+	call *chattypes.ToolCall,
+) (string, error) {
+	result, err := p.executeToolCall(ctx, currentPrompt.UserId, tools, call)
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if err != nil {
+		result = "Error: " + err.Error()
+	}
+
+	message := &chattypes.Message{
+		Id:         uuid.New().String(),
+		ThreadId:   currentPrompt.ThreadId,
+		ParentId:   parentId,
+		Role:       chattypes.MessageRoleTool,
+		Content:    escapeHtml(result),
+		ToolCallId: call.Id,
+	}
+
+	return message.Id, p.appService.AddMessage(message)
+}
+
+func (p *PromptService) executeToolCall(
+	ctx context.Context,
+	userId string,
+	tools []*tooltypes.Tool,
+	call *chattypes.ToolCall,
+) (string, error) {
+	var tool *tooltypes.Tool
+	for _, t := range tools {
+		if t.Id == call.Name {
+			tool = t
+		}
+	}
+	if tool == nil {
+		return "", fmt.Errorf("unknown tool '%v'", call.Name)
+	}
+
+	arguments := call.Arguments
+	if arguments == nil {
+		arguments = map[string]any{}
+	}
+	if tool.Parameters != nil {
+		err := validateSchema(arguments, tool.Parameters, "arguments")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	result, err := p.toolService.Execute(ctx, userId, tool.Id, arguments)
+	if err != nil {
+		return "", errors.Wrap(err, "error running tool")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,109 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chattypes "github.com/singulatron/singulatron/localtron/services/chat/types"
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+func TestParseToolCall(t *testing.T) {
+	text, call, ok := parseToolCall(`Let me look. <tool_call>{"name": "searchThreads", "arguments": {"query": "cats"}}`)
+	require.True(t, ok)
+	assert.Equal(t, "Let me look. ", text)
+	assert.Equal(t, "searchThreads", call.Name)
+	assert.Equal(t, map[string]any{"query": "cats"}, call.Arguments)
+	assert.NotEmpty(t, call.Id)
+
+	_, call, ok = parseToolCall(`<tool_call>{"name": "x", "arguments": {}}</tool_call> trailing`)
+	require.True(t, ok)
+	assert.Equal(t, "x", call.Name)
+
+	for _, answer := range []string{
+		"Cats are great.",
+		"<tool_call>not json",
+		`<tool_call>{"arguments": {}}`,
+	} {
+		_, _, ok = parseToolCall(answer)
+		assert.False(t, ok, answer)
+	}
+}
+
+func TestHistoryTurnsWithToolCalls(t *testing.T) {
+	messages := []*chattypes.Message{
+		{Id: "1", Role: chattypes.MessageRoleUser, Content: "find my cat threads"},
+		{Id: "2", Role: chattypes.MessageRoleAssistant, Content: "Let me look.", ToolCall: &chattypes.ToolCall{
+			Id:        "c1",
+			Name:      "searchThreads",
+			Arguments: map[string]any{"query": "cat"},
+		}},
+		{Id: "3", Role: chattypes.MessageRoleTool, Content: "[{&quot;title&quot;:&quot;Cats&quot;}]", ToolCallId: "c1"},
+	}
+
+	turns := historyTurns(messages, "", 10)
+	require.Equal(t, 3, len(turns))
+	assert.Equal(t, `Let me look.<tool_call>{"arguments":{"query":"cat"},"name":"searchThreads"}</tool_call>`, turns[1].Content)
+	assert.Equal(t, chattypes.MessageRoleTool, turns[2].Role)
+	assert.Equal(t, `<tool_result>[{"title":"Cats"}]</tool_result>`, turns[2].Content)
+}
+
+func TestToolStepPrompt(t *testing.T) {
+	messages := []*chattypes.Message{
+		{Id: "1", Role: chattypes.MessageRoleUser, Content: "hi"},
+		{Id: "2", Role: chattypes.MessageRoleAssistant, Content: "hello"},
+		{Id: "prompt-1", Role: chattypes.MessageRoleUser, Content: "find my cat threads"},
+		{Id: "3", Role: chattypes.MessageRoleAssistant, ToolCall: &chattypes.ToolCall{
+			Id:        "c1",
+			Name:      "searchThreads",
+			Arguments: map[string]any{"query": "cat"},
+		}},
+		{Id: "4", Role: chattypes.MessageRoleTool, Content: "Cats", ToolCallId: "c1"},
+	}
+	currentPrompt := &prompttypes.Prompt{Id: "prompt-1", Prompt: "find my cat threads"}
+	templates := modeltypes.RoleTemplates{
+		System:    "[system] {prompt}\n",
+		User:      "[user] {prompt}\n",
+		Assistant: "[assistant] {prompt}\n",
+	}
+	cb := &contextBuilder{
+		tokenizer:     wordTokenizer{},
+		templates:     templates,
+		contextLength: 100,
+	}
+
+	// the first step answers the prompt
+	built, err := cb.build(historyTurns(messages[:3], "prompt-1", 10), promptTurns(messages[:3], currentPrompt))
+	require.NoError(t, err)
+	assert.Equal(t, "[user] hi\n[assistant] hello\n[user] find my cat threads\n", renderTurns(built.Turns, templates))
+
+	// the second step continues from the tool result
+	built, err = cb.build(historyTurns(messages, "prompt-1", 10), promptTurns(messages, currentPrompt))
+	require.NoError(t, err)
+	ids := []string{}
+	for _, turn := range built.Turns {
+		ids = append(ids, turn.MessageId)
+	}
+	assert.Equal(t, []string{"1", "2", "prompt-1", "3", "4"}, ids)
+	assert.Equal(t, "[user] hi\n[assistant] hello\n[user] find my cat threads\n"+
+		`[assistant] <tool_call>{"arguments":{"query":"cat"},"name":"searchThreads"}</tool_call>`+"\n"+
+		"[user] <tool_result>Cats</tool_result>\n", renderTurns(built.Turns, templates))
+
+	// prompts not saved to the thread yet come last
+	built, err = cb.build(historyTurns(messages[:2], "prompt-1", 10), promptTurns(messages[:2], currentPrompt))
+	require.NoError(t, err)
+	assert.Equal(t, "prompt-1", built.Turns[len(built.Turns)-1].MessageId)
+}
@@ -40,10 +40,44 @@ var PermissionPromptStream = usertypes.Permission{
 	Name: "Prompt Stream",
 }
 
+var PermissionPromptTemplateView = usertypes.Permission{
+	Id:   "prompt.template.view",
+	Name: "Prompt Template View",
+}
+
+var PermissionPromptTemplateEdit = usertypes.Permission{
+	Id:   "prompt.template.edit",
+	Name: "Prompt Template Edit",
+}
+
+var PermissionPromptPrioritize = usertypes.Permission{
+	Id:   "prompt.prioritize",
+	Name: "Prompt Prioritize",
+}
+
+var PermissionPromptStats = usertypes.Permission{
+	Id:   "prompt.stats",
+	Name: "Prompt Stats",
+}
+
+var PermissionPromptQuota = usertypes.Permission{
+	Id:   "prompt.quota",
+	Name: "Prompt Quota",
+}
+
 var PromptPermissions = []usertypes.Permission{
 	PermissionPromptCreate,
 	PermissionPromptView,
 	PermissionPromptEdit,
 	PermissionPromptDelete,
 	PermissionPromptStream,
+	PermissionPromptTemplateView,
+	PermissionPromptTemplateEdit,
+}
+
+// PromptAdminPermissions are only granted to admins
+var PromptAdminPermissions = []usertypes.Permission{
+	PermissionPromptPrioritize,
+	PermissionPromptStats,
+	PermissionPromptQuota,
 }
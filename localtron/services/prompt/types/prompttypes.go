@@ -11,8 +11,11 @@
 package prompttypes
 
 import (
+	"errors"
 	"sync"
 	"time"
+
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
 )
 
 type PromptStatus string
@@ -22,11 +25,36 @@ const (
 	PromptStatusRunning   PromptStatus = "running"
 	PromptStatusCompleted PromptStatus = "completed"
 	// Errored means it will be still retried
-	PromptStatusErrored   PromptStatus = "errored"
-	PromptStatusAbandoned PromptStatus = "abandone"
+	PromptStatusErrored PromptStatus = "errored"
+	// Abandoned means it failed too many times and won't be retried
+	// unless requeued manually
+	PromptStatusAbandoned PromptStatus = "abandoned"
 	PromptStatusCanceled  PromptStatus = "canceled"
 )
 
+// Priority levels of prompts. Prompts with a higher priority
+// are processed first. Any integer is a valid priority.
+const (
+	PromptPriorityLow    = -1
+	PromptPriorityNormal = 0
+	PromptPriorityHigh   = 1
+)
+
+// ContextStrategy decides what to leave out when the history of a thread
+// does not fit the context window of the model.
+type ContextStrategy string
+
+const (
+	// ContextStrategyDropOldest drops the oldest messages first
+	ContextStrategyDropOldest ContextStrategy = "drop-oldest"
+	// ContextStrategyKeepSystemLastN keeps the system messages and
+	// the last N messages of the thread
+	ContextStrategyKeepSystemLastN ContextStrategy = "keep-system-last-n"
+	// ContextStrategySummarize summarizes older messages into
+	// a synthetic message which is saved to the thread
+	ContextStrategySummarize ContextStrategy = "summarize"
+)
+
 // Prompt
 // @todo:
 // - message and prompt have a lot of overlap, rethink
@@ -37,6 +65,11 @@ type Prompt struct {
 
 	ThreadId string `json:"threadId"`
 	UserId   string `json:"userId"`
+	// ParentId is the message the prompt follows.
+	// Defaults to the last message of the active branch of the thread.
+	// Editing or regenerating the prompt of a user message is done
+	// by following its parent, which starts an alternative branch.
+	ParentId string `json:"parentId,omitempty"`
 	// Prompt is the message itself
 	//    What's a banana?
 	Prompt string `json:"prompt"`
@@ -48,13 +81,87 @@ type Prompt struct {
 	LastRun  time.Time    `json:"lastRun,omitempty"`
 	// how many times this was ran
 	// (retries are due to errors)
-	RunCount   int    `json:"runCount,omitempty"`
-	Error      string `json:"error,omitempty"`
-	MaxRetries int    `json:"maxRetries,omitempty"`
+	RunCount int    `json:"runCount,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// MaxRetries is the number of runs after which a failing prompt
+	// is abandoned. Zero means the default of the prompt service.
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// Priority of the prompt in the queue, see PromptPriorityNormal.
+	// Raising it above normal requires the prompt.prioritize permission.
+	Priority int `json:"priority,omitempty"`
+	// ContextStrategy overrides the strategy configured for the prompt service
+	ContextStrategy ContextStrategy `json:"contextStrategy,omitempty"`
+	// Parameters override the default sampling parameters of the model
+	Parameters *modeltypes.SamplingParameters `json:"parameters,omitempty"`
+	// ImageParameters control prompts of image generating platforms
+	ImageParameters *modeltypes.ImageParameters `json:"imageParameters,omitempty"`
+	// ResponseSchema is a JSON schema the answer must match.
+	// The answer is generated with a grammar made from the schema,
+	// and it is validated once finished. Answers failing validation
+	// are retried like errors. The parsed answer is saved as the
+	// structured field of the answer message.
+	// Can't be used together with ToolIds.
+	ResponseSchema map[string]any `json:"responseSchema,omitempty"`
+	// ToolIds are the tools the model can call while answering.
+	// Every call and result is saved to the thread as a message.
+	// Can't be used together with ResponseSchema.
+	ToolIds []string `json:"toolIds,omitempty"`
+	// MaxToolSteps is the number of tool calls after which
+	// the model has to answer. Zero means the default of the prompt service.
+	MaxToolSteps int `json:"maxToolSteps,omitempty"`
+	// Usage is what the last run of the prompt consumed
+	Usage *Usage `json:"usage,omitempty"`
 
 	mutex sync.Mutex
 }
 
+// Usage is the tokens and time a prompt took to answer
+type Usage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+	// GenerationMs is the wall-clock time of the generation in milliseconds
+	GenerationMs int64 `json:"generationMs"`
+}
+
+/*
+UsageStat is the usage of a user with a model on a day (UTC).
+The prompt service keeps these up to date as prompts finish.
+*/
+type UsageStat struct {
+	// Id is made of the day, the user id and the model id, see UsageStatId
+	Id      string `json:"id"`
+	Day     string `json:"day"`
+	UserId  string `json:"userId"`
+	ModelId string `json:"modelId"`
+	// Prompts is the number of prompts answered
+	Prompts          int   `json:"prompts"`
+	PromptTokens     int   `json:"promptTokens"`
+	CompletionTokens int   `json:"completionTokens"`
+	GenerationMs     int64 `json:"generationMs"`
+}
+
+// UsageStatDayFormat is the format of UsageStat.Day
+const UsageStatDayFormat = "2006-01-02"
+
+func UsageStatId(day, userId, modelId string) string {
+	return day + ":" + userId + ":" + modelId
+}
+
+func (u *UsageStat) GetId() string {
+	return u.Id
+}
+
+// Add adds the usage of a prompt to the stat
+func (u *UsageStat) Add(usage *Usage) {
+	u.Prompts++
+	if usage == nil {
+		return
+	}
+	u.PromptTokens += usage.PromptTokens
+	u.CompletionTokens += usage.CompletionTokens
+	u.GenerationMs += usage.GenerationMs
+}
+
 func (c *Prompt) GetId() string {
 	return c.Id
 }
@@ -71,12 +178,99 @@ type ListPromptsRequest struct{}
 
 type ListPromptsResponse struct {
 	Prompts []*Prompt `json:"prompts"`
+	// QueuePositions is the place of the waiting prompts
+	// in the queue of their model by prompt id, starting from 1
+	QueuePositions map[string]int `json:"queuePositions,omitempty"`
 }
 
 type RemovePromptRequest struct {
 	Prompt *Prompt `json:"prompt"`
 }
 
+type RetryPromptRequest struct {
+	PromptId string `json:"promptId"`
+}
+
+type RetryPromptResponse struct{}
+
+/*
+GetStatsRequest filters the usage stats. From and To are days
+in the UsageStatDayFormat, both inclusive. Empty fields don't filter.
+When ThreadId is set the stats are calculated from the prompts of the thread.
+*/
+type GetStatsRequest struct {
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	UserId   string `json:"userId,omitempty"`
+	ModelId  string `json:"modelId,omitempty"`
+	ThreadId string `json:"threadId,omitempty"`
+}
+
+type GetStatsResponse struct {
+	Stats []*UsageStat `json:"stats"`
+	// Total is the sum of the stats
+	Total *UsageStat `json:"total"`
+}
+
+/*
+Quota limits the prompts of the users it applies to.
+A quota applies either to a role or to a user.
+Zero limits are unlimited.
+*/
+type Quota struct {
+	Id     string `json:"id"`
+	RoleId string `json:"roleId,omitempty"`
+	UserId string `json:"userId,omitempty"`
+	// PromptsPerHour is the number of prompts that can be added in an hour
+	PromptsPerHour int `json:"promptsPerHour,omitempty"`
+	// TokensPerDay is the number of prompt and completion tokens
+	// that can be used in a day (UTC), see UsageStat
+	TokensPerDay int `json:"tokensPerDay,omitempty"`
+	// MaxQueued is the number of prompts that can wait in the queue
+	// or be processed at the same time
+	MaxQueued int `json:"maxQueued,omitempty"`
+}
+
+func (q *Quota) GetId() string {
+	return q.Id
+}
+
+func (q *Quota) Validate() error {
+	if (q.RoleId == "") == (q.UserId == "") {
+		return errors.New("a quota must have either a role id or a user id")
+	}
+	if q.PromptsPerHour < 0 || q.TokensPerDay < 0 || q.MaxQueued < 0 {
+		return errors.New("quota limits must not be negative")
+	}
+	return nil
+}
+
+type SaveQuotaRequest struct {
+	Quota *Quota `json:"quota"`
+}
+
+type SaveQuotaResponse struct {
+	Quota *Quota `json:"quota"`
+}
+
+type ListQuotasRequest struct{}
+
+type ListQuotasResponse struct {
+	Quotas []*Quota `json:"quotas"`
+}
+
+type DeleteQuotaRequest struct {
+	QuotaId string `json:"quotaId"`
+}
+
+type DeleteQuotaResponse struct{}
+
+type CancelPromptRequest struct {
+	PromptId string `json:"promptId"`
+}
+
+type CancelPromptResponse struct{}
+
 //
 // Events
 //
@@ -101,6 +295,17 @@ func (e EventPromptRemoved) Name() string {
 	return EventPromptRemovedName
 }
 
+const EventPromptCanceledName = "promptCanceled"
+
+type EventPromptCanceled struct {
+	PromptId string `json:"promptId"`
+	ThreadId string `json:"threadId"`
+}
+
+func (e EventPromptCanceled) Name() string {
+	return EventPromptCanceledName
+}
+
 const EventPromptProcessingStartedName = "promptProcessingStarted"
 
 type EventPromptProcessingStarted struct {
@@ -114,8 +319,9 @@ func (e EventPromptProcessingStarted) Name() string {
 const EventPromptProcessingFinishedName = "promptProcessingFinished"
 
 type EventPromptProcessingFinished struct {
-	PromptId string `json:"promptId"`
-	Error    string `json:"error,omitempty"`
+	PromptId string       `json:"promptId"`
+	Status   PromptStatus `json:"status"`
+	Error    string       `json:"error,omitempty"`
 }
 
 func (e EventPromptProcessingFinished) Name() string {
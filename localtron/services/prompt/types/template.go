@@ -0,0 +1,74 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package prompttypes
+
+import (
+	"time"
+
+	modeltypes "github.com/singulatron/singulatron/localtron/services/model/types"
+)
+
+/*
+Template is a reusable persona, eg. a "code reviewer" or an "SQL helper".
+Threads using a template get its system prompt and role templates.
+
+The system prompt and the role templates are Go text/templates
+rendered with the variables of the thread, eg.
+
+	You are reviewing {{.language}} code. Be {{.tone}}.
+
+The message content still replaces the {prompt} placeholder
+of the role templates after rendering.
+*/
+type Template struct {
+	Id          string    `json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	UserId      string    `json:"userId,omitempty"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+	// RoleTemplates override the role templates of the model, field by field
+	RoleTemplates *modeltypes.RoleTemplates `json:"roleTemplates,omitempty"`
+	Variables     []TemplateVariable        `json:"variables,omitempty"`
+}
+
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Default is used when the thread does not set the variable
+	Default string `json:"default,omitempty"`
+}
+
+func (t *Template) GetId() string {
+	return t.Id
+}
+
+type SaveTemplateRequest struct {
+	Template *Template `json:"template"`
+}
+
+type SaveTemplateResponse struct {
+	Template *Template `json:"template"`
+}
+
+type ListTemplatesRequest struct{}
+
+type ListTemplatesResponse struct {
+	Templates []*Template `json:"templates"`
+}
+
+type DeleteTemplateRequest struct {
+	TemplateId string `json:"templateId"`
+}
+
+type DeleteTemplateResponse struct{}
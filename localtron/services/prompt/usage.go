@@ -0,0 +1,172 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"sort"
+	"time"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+// countsUsage tells if the usage of a prompt counts towards the stats
+func countsUsage(prompt *prompttypes.Prompt) bool {
+	// canceled prompts consumed resources up to the cancellation
+	return prompt.Usage != nil &&
+		(prompt.Status == prompttypes.PromptStatusCompleted ||
+			prompt.Status == prompttypes.PromptStatusCanceled)
+}
+
+/*
+recordUsage adds the usage of a finished prompt
+to the stat of its user and model for the day.
+*/
+func (p *PromptService) recordUsage(prompt *prompttypes.Prompt, finishedAt time.Time) error {
+	p.usageMutex.Lock()
+	defer p.usageMutex.Unlock()
+
+	day := finishedAt.UTC().Format(prompttypes.UsageStatDayFormat)
+	id := prompttypes.UsageStatId(day, prompt.UserId, prompt.ModelId)
+
+	stat, found, err := p.usageStore.Query(
+		datastore.Id(id),
+	).FindOne()
+	if err != nil {
+		return err
+	}
+	if !found {
+		stat = &prompttypes.UsageStat{
+			Id:      id,
+			Day:     day,
+			UserId:  prompt.UserId,
+			ModelId: prompt.ModelId,
+		}
+	}
+
+	stat.Add(prompt.Usage)
+
+	return p.usageStore.Upsert(stat)
+}
+
+/*
+GetStats returns the daily usage stats matching the request ordered by day,
+and their total.
+*/
+func (p *PromptService) GetStats(req *prompttypes.GetStatsRequest) (*prompttypes.GetStatsResponse, error) {
+	var stats []*prompttypes.UsageStat
+
+	if req.ThreadId != "" {
+		prompts, err := p.promptsStore.Query(
+			datastore.Equal("threadId", req.ThreadId),
+		).Find()
+		if err != nil {
+			return nil, err
+		}
+		stats = promptUsageStats(prompts)
+	} else {
+		conditions := []datastore.Condition{}
+		if req.UserId != "" {
+			conditions = append(conditions, datastore.Equal("userId", req.UserId))
+		}
+		if req.ModelId != "" {
+			conditions = append(conditions, datastore.Equal("modelId", req.ModelId))
+		}
+		if len(conditions) == 0 {
+			conditions = append(conditions, datastore.All())
+		}
+
+		var err error
+		stats, err = p.usageStore.Query(
+			conditions[0], conditions[1:]...,
+		).Find()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stats = filterStats(stats, req)
+
+	return &prompttypes.GetStatsResponse{
+		Stats: stats,
+		Total: totalStats(stats),
+	}, nil
+}
+
+// promptUsageStats aggregates the usage of prompts per day, user and model like recordUsage
+func promptUsageStats(prompts []*prompttypes.Prompt) []*prompttypes.UsageStat {
+	byId := map[string]*prompttypes.UsageStat{}
+	stats := []*prompttypes.UsageStat{}
+
+	for _, prompt := range prompts {
+		if !countsUsage(prompt) {
+			continue
+		}
+
+		day := prompt.LastRun.UTC().Format(prompttypes.UsageStatDayFormat)
+		id := prompttypes.UsageStatId(day, prompt.UserId, prompt.ModelId)
+
+		stat, ok := byId[id]
+		if !ok {
+			stat = &prompttypes.UsageStat{
+				Id:      id,
+				Day:     day,
+				UserId:  prompt.UserId,
+				ModelId: prompt.ModelId,
+			}
+			byId[id] = stat
+			stats = append(stats, stat)
+		}
+
+		stat.Add(prompt.Usage)
+	}
+
+	return stats
+}
+
+func filterStats(stats []*prompttypes.UsageStat, req *prompttypes.GetStatsRequest) []*prompttypes.UsageStat {
+	ret := []*prompttypes.UsageStat{}
+	for _, stat := range stats {
+		// days sort lexically
+		if req.From != "" && stat.Day < req.From {
+			continue
+		}
+		if req.To != "" && stat.Day > req.To {
+			continue
+		}
+		if req.UserId != "" && stat.UserId != req.UserId {
+			continue
+		}
+		if req.ModelId != "" && stat.ModelId != req.ModelId {
+			continue
+		}
+		ret = append(ret, stat)
+	}
+
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Day < ret[j].Day
+	})
+
+	return ret
+}
+
+func totalStats(stats []*prompttypes.UsageStat) *prompttypes.UsageStat {
+	total := &prompttypes.UsageStat{}
+	for _, stat := range stats {
+		total.Prompts += stat.Prompts
+		total.PromptTokens += stat.PromptTokens
+		total.CompletionTokens += stat.CompletionTokens
+		total.GenerationMs += stat.GenerationMs
+	}
+
+	return total
+}
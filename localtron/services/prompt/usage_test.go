@@ -0,0 +1,75 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package promptservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	prompttypes "github.com/singulatron/singulatron/localtron/services/prompt/types"
+)
+
+func TestPromptUsageStats(t *testing.T) {
+	day1 := time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Hour)
+	completed := prompttypes.PromptStatusCompleted
+	canceled := prompttypes.PromptStatusCanceled
+
+	prompts := []*prompttypes.Prompt{
+		{UserId: "u1", ModelId: "m1", LastRun: day1, Status: completed, Usage: &prompttypes.Usage{PromptTokens: 10, CompletionTokens: 20, GenerationMs: 100}},
+		{UserId: "u1", ModelId: "m1", LastRun: day1, Status: canceled, Usage: &prompttypes.Usage{PromptTokens: 5, CompletionTokens: 5, GenerationMs: 50}},
+		{UserId: "u1", ModelId: "m1", LastRun: day2, Status: completed, Usage: &prompttypes.Usage{PromptTokens: 1, CompletionTokens: 2, GenerationMs: 3}},
+		{UserId: "u2", ModelId: "m1", LastRun: day1, Status: completed, Usage: &prompttypes.Usage{PromptTokens: 7, CompletionTokens: 8, GenerationMs: 9}},
+		// never ran
+		{UserId: "u2", ModelId: "m1"},
+		// failed runs are not recorded either
+		{UserId: "u2", ModelId: "m1", LastRun: day1, Status: prompttypes.PromptStatusErrored, Usage: &prompttypes.Usage{PromptTokens: 100}},
+		{UserId: "u2", ModelId: "m1", LastRun: day1, Status: prompttypes.PromptStatusAbandoned, Usage: &prompttypes.Usage{PromptTokens: 100}},
+	}
+
+	stats := promptUsageStats(prompts)
+	require.Equal(t, 3, len(stats))
+
+	assert.Equal(t, &prompttypes.UsageStat{
+		Id:               "2024-05-01:u1:m1",
+		Day:              "2024-05-01",
+		UserId:           "u1",
+		ModelId:          "m1",
+		Prompts:          2,
+		PromptTokens:     15,
+		CompletionTokens: 25,
+		GenerationMs:     150,
+	}, stats[0])
+	assert.Equal(t, "2024-05-02", stats[1].Day)
+
+	filtered := filterStats(stats, &prompttypes.GetStatsRequest{
+		From:   "2024-05-01",
+		To:     "2024-05-01",
+		UserId: "u1",
+	})
+	require.Equal(t, 1, len(filtered))
+	assert.Equal(t, "2024-05-01:u1:m1", filtered[0].Id)
+
+	filtered = filterStats(stats, &prompttypes.GetStatsRequest{
+		From: "2024-05-02",
+	})
+	require.Equal(t, 1, len(filtered))
+	assert.Equal(t, "u1", filtered[0].UserId)
+
+	total := totalStats(stats)
+	assert.Equal(t, 4, total.Prompts)
+	assert.Equal(t, 23, total.PromptTokens)
+	assert.Equal(t, 35, total.CompletionTokens)
+	assert.Equal(t, int64(162), total.GenerationMs)
+}
@@ -54,6 +54,19 @@ func llmResponseToText(responses []*llm.CompletionResponse) string {
 	return result.String()
 }
 
+// llmResponseToRawText joins the streamed responses without any escaping
+func llmResponseToRawText(responses []*llm.CompletionResponse) string {
+	var result strings.Builder
+	for _, v := range responses {
+		if len(v.Choices) == 0 {
+			continue
+		}
+		result.WriteString(v.Choices[0].Text)
+	}
+
+	return result.String()
+}
+
 func escapeHtml(input string) string {
 	replacer := strings.NewReplacer(
 		"&", "&amp;",
@@ -64,11 +77,3 @@ func escapeHtml(input string) string {
 	)
 	return replacer.Replace(input)
 }
-
-func errToString(err error) string {
-	if err != nil {
-		return err.Error()
-	}
-
-	return ""
-}
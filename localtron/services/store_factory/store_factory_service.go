@@ -17,6 +17,8 @@ import (
 	"github.com/singulatron/singulatron/localtron/datastore"
 	"github.com/singulatron/singulatron/localtron/datastore/localstore"
 	"github.com/singulatron/singulatron/localtron/datastore/sqlstore"
+	"github.com/singulatron/singulatron/localtron/filestore"
+	"github.com/singulatron/singulatron/localtron/filestore/localfilestore"
 )
 
 var LocalStorePath = ""
@@ -51,3 +53,9 @@ func GetStore[T datastore.Row](tableName string) (datastore.DataStore[T], error)
 	}
 	return localstore.NewLocalStore[T](path.Join(LocalStorePath, tableName)), nil
 }
+
+// GetFileStore returns the store of the files of a given kind, eg. chat assets.
+// Only local files are supported for now, the interface leaves room for S3 compatible stores.
+func GetFileStore(name string) (filestore.FileStore, error) {
+	return localfilestore.NewLocalFileStore(path.Join(LocalStorePath, name)), nil
+}
@@ -0,0 +1,148 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package toolservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+
+	tooltypes "github.com/singulatron/singulatron/localtron/services/tool/types"
+)
+
+// the most items builtin tools return so results fit the context window
+const maxBuiltinResults = 20
+
+type builtin struct {
+	tool *tooltypes.Tool
+	run  func(ctx context.Context, userId string, arguments map[string]any) (any, error)
+}
+
+func (t *ToolService) builtinTools() map[string]*builtin {
+	builtins := []*builtin{
+		{
+			tool: &tooltypes.Tool{
+				Id:          "searchThreads",
+				Description: "Searches the chat threads of the user by title.",
+				Parameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"query": map[string]any{"type": "string"},
+					},
+					"required": []any{"query"},
+				},
+			},
+			run: t.searchThreads,
+		},
+		{
+			tool: &tooltypes.Tool{
+				Id: "queryGenericTable",
+				Description: "Lists the entries of a table of the generic store visible to the user. " +
+					"Entries can be filtered by a field, eg. \"data.name\", being equal to a value.",
+				Parameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"table": map[string]any{"type": "string"},
+						"field": map[string]any{"type": "string"},
+						"value": map[string]any{"type": "string"},
+					},
+					"required": []any{"table"},
+				},
+			},
+			run: t.queryGenericTable,
+		},
+	}
+
+	ret := map[string]*builtin{}
+	for _, b := range builtins {
+		b.tool.Type = tooltypes.ToolTypeBuiltin
+		ret[b.tool.Id] = b
+	}
+
+	return ret
+}
+
+// registerBuiltins saves the builtin tools so they are listed with the others
+func (t *ToolService) registerBuiltins() error {
+	now := timeNow()
+	for _, b := range t.builtins {
+		b.tool.CreatedAt = now
+		b.tool.UpdatedAt = now
+		err := t.toolsStore.Upsert(b.tool)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *ToolService) searchThreads(ctx context.Context, userId string, arguments map[string]any) (any, error) {
+	query, _ := arguments["query"].(string)
+	query = strings.ToLower(query)
+
+	threads, err := t.chatService.GetThreads(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []map[string]any{}
+	for _, thread := range threads {
+		if !strings.Contains(strings.ToLower(thread.Title), query) {
+			continue
+		}
+		ret = append(ret, map[string]any{
+			"id":        thread.Id,
+			"title":     thread.Title,
+			"createdAt": thread.CreatedAt,
+		})
+		if len(ret) == maxBuiltinResults {
+			break
+		}
+	}
+
+	return ret, nil
+}
+
+func (t *ToolService) queryGenericTable(ctx context.Context, userId string, arguments map[string]any) (any, error) {
+	table, _ := arguments["table"].(string)
+	if table == "" {
+		return nil, fmt.Errorf("table is missing")
+	}
+
+	condition := datastore.All()
+	if field, _ := arguments["field"].(string); field != "" {
+		condition = datastore.Equal(field, arguments["value"])
+	}
+
+	objects, err := t.genericService.Find(table, userId, []datastore.Condition{condition})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []map[string]any{}
+	for _, object := range objects {
+		if object.UserId != userId && !object.Public {
+			continue
+		}
+		ret = append(ret, map[string]any{
+			"id":   object.Id,
+			"data": object.Data,
+		})
+		if len(ret) == maxBuiltinResults {
+			break
+		}
+	}
+
+	return ret, nil
+}
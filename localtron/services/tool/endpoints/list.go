@@ -0,0 +1,52 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package toolendpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	toolservice "github.com/singulatron/singulatron/localtron/services/tool"
+	tooltypes "github.com/singulatron/singulatron/localtron/services/tool/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+func List(
+	w http.ResponseWriter,
+	r *http.Request,
+	userService *userservice.UserService,
+	toolService *toolservice.ToolService,
+) {
+	err := userService.IsAuthorized(tooltypes.PermissionToolView.Id, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	req := tooltypes.ListToolsRequest{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, `invalid JSON`, http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	tools, err := toolService.ListTools()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bs, _ := json.Marshal(tooltypes.ListToolsResponse{
+		Tools: tools,
+	})
+	w.Write(bs)
+}
@@ -0,0 +1,121 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package toolservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	tooltypes "github.com/singulatron/singulatron/localtron/services/tool/types"
+)
+
+const (
+	toolTimeout = 30 * time.Second
+	// results are cut to this many bytes so they fit the context window
+	maxResultSize = 16 * 1024
+)
+
+var (
+	ErrToolNotFound = errors.New("tool not found")
+	timeNow         = time.Now
+)
+
+/*
+Execute runs a tool for a user and returns its result as text.
+Arguments are expected to be validated against the parameters of the tool.
+*/
+func (t *ToolService) Execute(
+	ctx context.Context,
+	userId string,
+	toolId string,
+	arguments map[string]any,
+) (string, error) {
+	if b, ok := t.builtins[toolId]; ok {
+		result, err := b.run(ctx, userId, arguments)
+		if err != nil {
+			return "", err
+		}
+		bs, err := json.Marshal(result)
+		if err != nil {
+			return "", err
+		}
+		return truncate(string(bs)), nil
+	}
+
+	tool, found, err := t.toolsStore.Query(
+		datastore.Id(toolId),
+	).FindOne()
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", ErrToolNotFound
+	}
+
+	return t.executeHttp(ctx, userId, tool, arguments)
+}
+
+func (t *ToolService) executeHttp(
+	ctx context.Context,
+	userId string,
+	tool *tooltypes.Tool,
+	arguments map[string]any,
+) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, toolTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(tooltypes.ToolCallRequest{
+		Tool:      tool.Id,
+		Arguments: arguments,
+		UserId:    userId,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tool.Url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "error calling tool")
+	}
+	defer rsp.Body.Close()
+
+	bs, err := io.ReadAll(io.LimitReader(rsp.Body, maxResultSize+1))
+	if err != nil {
+		return "", errors.Wrap(err, "error reading tool response")
+	}
+
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		return "", fmt.Errorf("tool responded with status %v: %v", rsp.StatusCode, truncate(string(bs)))
+	}
+
+	return truncate(string(bs)), nil
+}
+
+func truncate(result string) string {
+	if len(result) <= maxResultSize {
+		return result
+	}
+	return result[:maxResultSize] + "..."
+}
@@ -0,0 +1,33 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package toolservice
+
+import (
+	"github.com/singulatron/singulatron/localtron/datastore"
+	tooltypes "github.com/singulatron/singulatron/localtron/services/tool/types"
+)
+
+func (t *ToolService) ListTools() ([]*tooltypes.Tool, error) {
+	return t.toolsStore.Query(
+		datastore.All(),
+	).OrderBy("createdAt", false).Find()
+}
+
+// GetTools returns the tools with the given ids, skipping the ones not found
+func (t *ToolService) GetTools(toolIds []string) ([]*tooltypes.Tool, error) {
+	if len(toolIds) == 0 {
+		return nil, nil
+	}
+
+	return t.toolsStore.Query(
+		datastore.Equal("id", toolIds),
+	).Find()
+}
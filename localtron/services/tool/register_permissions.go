@@ -0,0 +1,48 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+
+package toolservice
+
+import (
+	tooltypes "github.com/singulatron/singulatron/localtron/services/tool/types"
+	usertypes "github.com/singulatron/singulatron/localtron/services/user/types"
+)
+
+func (t *ToolService) registerPermissions() error {
+	for _, permission := range append(
+		tooltypes.ToolPermissions,
+		tooltypes.ToolAdminPermissions...,
+	) {
+		_, err := t.userService.UpsertPermission(
+			permission.Id,
+			permission.Name,
+			permission.Description,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, role := range []*usertypes.Role{
+		usertypes.RoleAdmin,
+		usertypes.RoleUser,
+	} {
+		for _, permission := range tooltypes.ToolPermissions {
+			t.userService.AddPermissionToRole(role.Id, permission.Id)
+		}
+	}
+
+	for _, permission := range tooltypes.ToolAdminPermissions {
+		t.userService.AddPermissionToRole(usertypes.RoleAdmin.Id, permission.Id)
+	}
+
+	return nil
+}
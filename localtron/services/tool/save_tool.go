@@ -0,0 +1,83 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package toolservice
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/singulatron/singulatron/localtron/datastore"
+	tooltypes "github.com/singulatron/singulatron/localtron/services/tool/types"
+)
+
+var (
+	ErrBuiltinTool = errors.New("builtin tools can't be changed")
+	ErrInvalidTool = errors.New("invalid tool")
+)
+
+var toolIdPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// SaveTool creates or updates an http tool
+func (t *ToolService) SaveTool(tool *tooltypes.Tool) (*tooltypes.Tool, error) {
+	if _, ok := t.builtins[tool.Id]; ok || tool.Type == tooltypes.ToolTypeBuiltin {
+		return nil, ErrBuiltinTool
+	}
+
+	err := validateTool(tool)
+	if err != nil {
+		return nil, err
+	}
+
+	now := timeNow()
+	tool.UpdatedAt = now
+	tool.CreatedAt = now
+
+	existing, found, err := t.toolsStore.Query(
+		datastore.Id(tool.Id),
+	).FindOne()
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		tool.CreatedAt = existing.CreatedAt
+	}
+
+	err = t.toolsStore.Upsert(tool)
+	if err != nil {
+		return nil, err
+	}
+
+	return tool, nil
+}
+
+func validateTool(tool *tooltypes.Tool) error {
+	if !toolIdPattern.MatchString(tool.Id) {
+		return fmt.Errorf("%w: tool ids can only have letters, digits, _ and -", ErrInvalidTool)
+	}
+	if tool.Type != tooltypes.ToolTypeHttp {
+		return fmt.Errorf("%w: unknown tool type", ErrInvalidTool)
+	}
+
+	u, err := url.Parse(tool.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: tool url must be an http or https url", ErrInvalidTool)
+	}
+
+	if tool.Parameters != nil {
+		if typ, _ := tool.Parameters["type"].(string); typ != "object" {
+			return fmt.Errorf("%w: tool parameters must be an object schema", ErrInvalidTool)
+		}
+	}
+
+	return nil
+}
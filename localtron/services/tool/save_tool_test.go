@@ -0,0 +1,67 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package toolservice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tooltypes "github.com/singulatron/singulatron/localtron/services/tool/types"
+)
+
+func TestValidateTool(t *testing.T) {
+	tests := []struct {
+		name  string
+		tool  *tooltypes.Tool
+		valid bool
+	}{
+		{
+			name: "Valid",
+			tool: &tooltypes.Tool{
+				Id:         "weather",
+				Type:       tooltypes.ToolTypeHttp,
+				Url:        "http://127.0.0.1:8080/weather",
+				Parameters: map[string]any{"type": "object"},
+			},
+			valid: true,
+		},
+		{
+			name: "Invalid id",
+			tool: &tooltypes.Tool{Id: "the weather", Type: tooltypes.ToolTypeHttp, Url: "http://x"},
+		},
+		{
+			name: "Not an http url",
+			tool: &tooltypes.Tool{Id: "weather", Type: tooltypes.ToolTypeHttp, Url: "file:///etc/passwd"},
+		},
+		{
+			name: "Parameters not an object",
+			tool: &tooltypes.Tool{
+				Id:         "weather",
+				Type:       tooltypes.ToolTypeHttp,
+				Url:        "http://x",
+				Parameters: map[string]any{"type": "string"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTool(tt.tool)
+			if tt.valid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, ErrInvalidTool))
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package toolservice
+
+import (
+	"github.com/singulatron/singulatron/localtron/datastore"
+
+	chatservice "github.com/singulatron/singulatron/localtron/services/chat"
+	configservice "github.com/singulatron/singulatron/localtron/services/config"
+	genericservice "github.com/singulatron/singulatron/localtron/services/generic"
+	storefactoryservice "github.com/singulatron/singulatron/localtron/services/store_factory"
+	tooltypes "github.com/singulatron/singulatron/localtron/services/tool/types"
+	userservice "github.com/singulatron/singulatron/localtron/services/user"
+)
+
+type ToolService struct {
+	configService  *configservice.ConfigService
+	userService    *userservice.UserService
+	chatService    *chatservice.ChatService
+	genericService *genericservice.GenericService
+
+	toolsStore datastore.DataStore[*tooltypes.Tool]
+
+	builtins map[string]*builtin
+}
+
+func NewToolService(
+	cs *configservice.ConfigService,
+	userService *userservice.UserService,
+	chatService *chatservice.ChatService,
+	genericService *genericservice.GenericService,
+) (*ToolService, error) {
+	toolsStore, err := storefactoryservice.GetStore[*tooltypes.Tool]("tools")
+	if err != nil {
+		return nil, err
+	}
+
+	service := &ToolService{
+		configService:  cs,
+		userService:    userService,
+		chatService:    chatService,
+		genericService: genericService,
+
+		toolsStore: toolsStore,
+	}
+	service.builtins = service.builtinTools()
+
+	err = service.registerPermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.registerBuiltins()
+	if err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
@@ -0,0 +1,73 @@
+/**
+ * @license
+ * Copyright (c) The Authors (see the AUTHORS file)
+ *
+ * This source code is licensed under the GNU Affero General Public License v3.0 (AGPLv3) for personal, non-commercial use.
+ * You may obtain a copy of the AGPL v3.0 at https://www.gnu.org/licenses/agpl-3.0.html.
+ *
+ * For commercial use, a separate license must be obtained by purchasing from The Authors.
+ * For commercial licensing inquiries, please contact The Authors listed in the AUTHORS file.
+ */
+package tooltypes
+
+import (
+	"time"
+)
+
+type ToolType string
+
+const (
+	// ToolTypeHttp tools are run by posting the call to their url
+	ToolTypeHttp ToolType = "http"
+	// ToolTypeBuiltin tools are run by the tool service itself
+	ToolTypeBuiltin ToolType = "builtin"
+)
+
+/*
+Tool is something models can call while answering a prompt.
+Calls of http tools are posted to their url as a ToolCallRequest
+and the response body is the result passed to the model.
+*/
+type Tool struct {
+	// Id is the name models call the tool by
+	Id          string    `json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	Description string    `json:"description"`
+	// Parameters is the JSON schema of the arguments of the tool
+	Parameters map[string]any `json:"parameters,omitempty"`
+	Type       ToolType       `json:"type"`
+	Url        string         `json:"url,omitempty"`
+}
+
+func (t *Tool) GetId() string {
+	return t.Id
+}
+
+// ToolCallRequest is what http tools receive
+type ToolCallRequest struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	// UserId is the user whose prompt called the tool
+	UserId string `json:"userId"`
+}
+
+type SaveToolRequest struct {
+	Tool *Tool `json:"tool"`
+}
+
+type SaveToolResponse struct {
+	Tool *Tool `json:"tool"`
+}
+
+type ListToolsRequest struct{}
+
+type ListToolsResponse struct {
+	Tools []*Tool `json:"tools"`
+}
+
+type DeleteToolRequest struct {
+	ToolId string `json:"toolId"`
+}
+
+type DeleteToolResponse struct{}
@@ -34,6 +34,15 @@ func (c *User) GetUpdatedAt() string {
 	return c.Id
 }
 
+func (c *User) HasRole(roleId string) bool {
+	for _, id := range c.RoleIds {
+		if id == roleId {
+			return true
+		}
+	}
+	return false
+}
+
 type ReadUserByTokenRequest struct {
 	Token string `json:"token,omitempty"`
 }